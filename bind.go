@@ -0,0 +1,257 @@
+package oracle
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"math"
+	"reflect"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// bindBool turns a bool, also behind a pointer or in a sql.NullBool, into the 1/0 it is bound as,
+// which NUMBER(1) columns hold and BOOLEAN ones (23ai) take as well, leaving any other value untouched
+func bindBool(v interface{}) interface{} {
+	switch nullBool := v.(type) {
+	case *sql.NullBool:
+		if nullBool == nil {
+			return nil
+		}
+		return bindBool(*nullBool)
+	case sql.NullBool:
+		if !nullBool.Valid {
+			return nil
+		}
+		v = nullBool.Bool
+	case driver.Valuer:
+		return v
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Ptr && rv.Type().Elem().Kind() == reflect.Bool {
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Bool {
+		return v
+	}
+	if rv.Bool() {
+		return 1
+	}
+	return 0
+}
+
+// bindValueOf reduces v to the plain value godror binds for it, e.g. pointers are
+// dereferenced, driver.Valuer is resolved and booleans become 1/0
+func bindValueOf(v interface{}) interface{} {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+	if !rv.IsValid() {
+		return nil
+	}
+
+	if valuer, ok := rv.Interface().(driver.Valuer); ok {
+		value, err := valuer.Value()
+		if err != nil {
+			return v
+		}
+		if rv = reflect.ValueOf(value); !rv.IsValid() {
+			return nil
+		}
+	}
+
+	switch rv.Kind() {
+	case reflect.Bool:
+		if rv.Bool() {
+			return int64(1)
+		}
+		return int64(0)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if u := rv.Uint(); u <= math.MaxInt64 {
+			return int64(u)
+		}
+		return rv.Uint()
+	case reflect.Float32, reflect.Float64:
+		return rv.Float()
+	case reflect.String:
+		return rv.String()
+	}
+	return rv.Interface()
+}
+
+// arrayBindVars transposes the rows of values into one typed slice per column, which
+// godror binds as an array so that the whole batch executes in a single round trip.
+// A column holding the same SQL expression without vars in every row, e.g.
+// gorm.Expr("SYSTIMESTAMP"), is rendered inline instead, row is the one to build the
+// statement with. It reports false when some column holds values that can not share
+// one array bind.
+func arrayBindVars(values clause.Values) (row []interface{}, vars []interface{}, ok bool) {
+	row = make([]interface{}, len(values.Columns))
+	for idx := range values.Columns {
+		if expr, shared := sharedExpr(values, idx); shared {
+			row[idx] = expr
+			continue
+		}
+
+		column := make([]interface{}, len(values.Values))
+		for r, vals := range values.Values {
+			if len(vals) != len(values.Columns) || isExpression(vals[idx]) {
+				return nil, nil, false
+			}
+			column[r] = bindValueOf(vals[idx])
+		}
+
+		array, ok := arrayOf(column)
+		if !ok {
+			return nil, nil, false
+		}
+		row[idx] = column[0]
+		vars = append(vars, array)
+	}
+	return row, vars, true
+}
+
+// sharedExpr reports whether every row holds the same SQL expression without vars at idx
+func sharedExpr(values clause.Values, idx int) (clause.Expr, bool) {
+	var first clause.Expr
+	for r, vals := range values.Values {
+		if len(vals) != len(values.Columns) {
+			return first, false
+		}
+		expr, ok := vals[idx].(clause.Expr)
+		if !ok || len(expr.Vars) > 0 || (r > 0 && expr.SQL != first.SQL) {
+			return first, false
+		}
+		if r == 0 {
+			first = expr
+		}
+	}
+	return first, true
+}
+
+// isExpression reports whether v is rendered as SQL rather than bound as a value
+func isExpression(v interface{}) bool {
+	switch v.(type) {
+	case clause.Expression, *gorm.DB, gorm.Valuer:
+		return true
+	}
+	return false
+}
+
+// arrayOf builds the typed slice for a column of bind values, using the sql.Null*
+// variants when some of them are NULL
+func arrayOf(column []interface{}) (interface{}, bool) {
+	var (
+		typ    reflect.Type
+		hasNil bool
+	)
+	for _, v := range column {
+		switch {
+		case v == nil:
+			hasNil = true
+		case typ == nil:
+			typ = reflect.TypeOf(v)
+		case typ != reflect.TypeOf(v):
+			return nil, false
+		}
+	}
+
+	switch typ {
+	case nil, reflect.TypeOf(""):
+		// an empty string is NULL to oracle anyway
+		array := make([]string, len(column))
+		for idx, v := range column {
+			array[idx], _ = v.(string)
+		}
+		return array, true
+	case reflect.TypeOf(int64(0)):
+		if !hasNil {
+			array := make([]int64, len(column))
+			for idx, v := range column {
+				array[idx] = v.(int64)
+			}
+			return array, true
+		}
+		array := make([]sql.NullInt64, len(column))
+		for idx, v := range column {
+			array[idx].Int64, array[idx].Valid = v.(int64)
+		}
+		return array, true
+	case reflect.TypeOf(uint64(0)):
+		if hasNil {
+			return nil, false
+		}
+		array := make([]uint64, len(column))
+		for idx, v := range column {
+			array[idx] = v.(uint64)
+		}
+		return array, true
+	case reflect.TypeOf(float64(0)):
+		if !hasNil {
+			array := make([]float64, len(column))
+			for idx, v := range column {
+				array[idx] = v.(float64)
+			}
+			return array, true
+		}
+		array := make([]sql.NullFloat64, len(column))
+		for idx, v := range column {
+			array[idx].Float64, array[idx].Valid = v.(float64)
+		}
+		return array, true
+	case reflect.TypeOf(time.Time{}):
+		if !hasNil {
+			array := make([]time.Time, len(column))
+			for idx, v := range column {
+				array[idx] = v.(time.Time)
+			}
+			return array, true
+		}
+		array := make([]sql.NullTime, len(column))
+		for idx, v := range column {
+			array[idx].Time, array[idx].Valid = v.(time.Time)
+		}
+		return array, true
+	case reflect.TypeOf([]byte(nil)):
+		array := make([][]byte, len(column))
+		for idx, v := range column {
+			array[idx], _ = v.([]byte)
+		}
+		return array, true
+	}
+	return nil, false
+}
+
+// outArrayOf makes a pointer to an empty slice that godror can collect the values returned for
+// field into, using the sql.Null* variants as a returned column may always be NULL
+func outArrayOf(field *schema.Field, capacity int) interface{} {
+	var elem reflect.Type
+	switch field.DataType {
+	case schema.Bool, schema.Int, schema.Uint:
+		elem = reflect.TypeOf(sql.NullInt64{})
+	case schema.Float:
+		elem = reflect.TypeOf(sql.NullFloat64{})
+	case schema.Time:
+		elem = reflect.TypeOf(sql.NullTime{})
+	case schema.Bytes:
+		elem = reflect.TypeOf([]byte(nil))
+	default:
+		elem = reflect.TypeOf("")
+	}
+
+	dest := reflect.New(reflect.SliceOf(elem))
+	dest.Elem().Set(reflect.MakeSlice(dest.Elem().Type(), 0, capacity))
+	return dest.Interface()
+}
@@ -0,0 +1,228 @@
+package oracle
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/thoas/go-funk"
+	"gorm.io/gorm"
+	"gorm.io/gorm/callbacks"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// yesNoSettingKey marks the statements whose SET clause SerializeYesNo built
+const yesNoSettingKey = "oracle:yesno_set"
+
+func init() {
+	schema.RegisterSerializer("yesno", YesNo{})
+}
+
+// YesNo is the serializer of bool fields kept in CHAR(1) columns as 'Y' and 'N', as legacy schemas
+// do, e.g. gorm:"serializer:yesno". It scans BOOLEAN and NUMBER(1) columns as well. Bools compared
+// to such columns by conditions of maps and structs are written as 'Y' and 'N' too, those given to
+// conditions written in SQL, e.g. Where("FLAG = ?", true), are not as their column is unknown
+type YesNo struct{}
+
+// isYesNo reports whether field is kept as 'Y' and 'N'
+func isYesNo(field *schema.Field) bool {
+	_, ok := field.Serializer.(YesNo)
+	return ok
+}
+
+func (YesNo) Scan(ctx context.Context, field *schema.Field, dst reflect.Value, dbValue interface{}) error {
+	fieldValue := reflect.New(field.FieldType)
+	if dbValue != nil {
+		b, err := scanBool(dbValue)
+		if err != nil {
+			return fmt.Errorf("failed to scan %v into field %s: %w", dbValue, field.Name, err)
+		}
+
+		value := fieldValue.Elem()
+		if value.Kind() == reflect.Ptr {
+			value.Set(reflect.New(field.FieldType.Elem()))
+			value = value.Elem()
+		}
+		value.SetBool(b)
+	}
+	field.ReflectValueOf(ctx, dst).Set(fieldValue.Elem())
+	return nil
+}
+
+func (YesNo) Value(ctx context.Context, field *schema.Field, dst reflect.Value, fieldValue interface{}) (interface{}, error) {
+	rv := reflect.ValueOf(fieldValue)
+	if rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil, nil
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Bool {
+		return nil, fmt.Errorf("invalid value %v of yesno field %s", fieldValue, field.Name)
+	}
+	if rv.Bool() {
+		return "Y", nil
+	}
+	return "N", nil
+}
+
+// scanBool reads a bool out of the value of a BOOLEAN, NUMBER(1) or CHAR(1) column
+func scanBool(v interface{}) (bool, error) {
+	if b, ok := v.([]byte); ok {
+		v = string(b)
+	}
+
+	switch rv := reflect.ValueOf(v); rv.Kind() {
+	case reflect.Bool:
+		return rv.Bool(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int() != 0, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return rv.Uint() != 0, nil
+	case reflect.Float32, reflect.Float64:
+		return rv.Float() != 0, nil
+	case reflect.String:
+		// numbers come as godror.Number
+		switch strings.ToUpper(strings.TrimSpace(rv.String())) {
+		case "Y", "YES", "T", "TRUE", "1":
+			return true, nil
+		case "N", "NO", "F", "FALSE", "0":
+			return false, nil
+		}
+	}
+	return false, fmt.Errorf("invalid bool %v", v)
+}
+
+// yesNoValue writes a bool given for a yesno field as 'Y' or 'N', gorm binds the values of maps as
+// they are, without the serializer of the field they are for
+func yesNoValue(field *schema.Field, v interface{}) interface{} {
+	if field == nil || !isYesNo(field) {
+		return v
+	}
+	switch v.(type) {
+	case bool, *bool:
+		if value, err := (YesNo{}).Value(context.Background(), field, reflect.Value{}, v); err == nil {
+			return value
+		}
+	}
+	return v
+}
+
+// lookUpColumn finds the field of name in s, as gorm does, or else by its column name in any case,
+// as unquoted names are, e.g. flag for FLAG
+func lookUpColumn(s *schema.Schema, name string) *schema.Field {
+	if field := s.LookUpField(name); field != nil {
+		return field
+	}
+	for _, field := range s.Fields {
+		if field.DBName != "" && strings.EqualFold(field.DBName, name) {
+			return field
+		}
+	}
+	return nil
+}
+
+// SerializeYesNo builds the SET clause of an update by a map the way gorm would, writing the bools
+// of yesno fields as 'Y' and 'N'. The map is left as it is, the model is assigned the bools
+func SerializeYesNo(db *gorm.DB) {
+	stmt := db.Statement
+	if db.Error != nil || stmt.Schema == nil || stmt.SQL.Len() > 0 {
+		return
+	}
+	if _, ok := stmt.Clauses["SET"]; ok {
+		return
+	}
+	if _, ok := stmt.Dest.(map[string]interface{}); !ok || !funk.Contains(stmt.Schema.Fields, isYesNo) {
+		return
+	}
+
+	set := callbacks.ConvertToAssignments(stmt)
+	if len(set) == 0 {
+		return
+	}
+	for idx, assignment := range set {
+		set[idx].Value = yesNoValue(lookUpColumn(stmt.Schema, assignment.Column.Name), assignment.Value)
+	}
+	stmt.AddClause(set)
+	stmt.Settings.Store(yesNoSettingKey, true)
+}
+
+// ResetYesNo drops the SET clause SerializeYesNo built once the update is done, as gorm does with its own
+func ResetYesNo(db *gorm.DB) {
+	if _, ok := db.Statement.Settings.LoadAndDelete(yesNoSettingKey); ok {
+		delete(db.Statement.Clauses, "SET")
+	}
+}
+
+// SerializeYesNoConditions writes the bools compared to yesno columns by the conditions of maps and
+// structs as 'Y' and 'N', gorm compares them to the field values as they are
+func SerializeYesNoConditions(db *gorm.DB) {
+	stmt := db.Statement
+	if db.Error != nil || stmt.Schema == nil || stmt.SQL.Len() > 0 || !funk.Contains(stmt.Schema.Fields, isYesNo) {
+		return
+	}
+
+	c, ok := stmt.Clauses["WHERE"]
+	if where, isWhere := c.Expression.(clause.Where); ok && isWhere {
+		// the expressions may be shared with the statement this one was cloned from, so they are copied
+		if exprs, changed := yesNoConditions(stmt.Schema, where.Exprs); changed {
+			c.Expression = clause.Where{Exprs: exprs}
+			stmt.Clauses["WHERE"] = c
+		}
+	}
+}
+
+// yesNoConditions returns a copy of exprs comparing yesno columns to 'Y' and 'N' rather than bools,
+// reporting whether there were any
+func yesNoConditions(s *schema.Schema, exprs []clause.Expression) ([]clause.Expression, bool) {
+	var changed bool
+	converted := make([]clause.Expression, len(exprs))
+	for idx, expr := range exprs {
+		switch e := expr.(type) {
+		case clause.Eq:
+			if field := lookUpColumn(s, columnNameOf(e.Column)); field != nil && isYesNo(field) {
+				e.Value, changed = yesNoValue(field, e.Value), true
+			}
+			expr = e
+		case clause.Neq:
+			if field := lookUpColumn(s, columnNameOf(e.Column)); field != nil && isYesNo(field) {
+				e.Value, changed = yesNoValue(field, e.Value), true
+			}
+			expr = e
+		case clause.IN:
+			if field := lookUpColumn(s, columnNameOf(e.Column)); field != nil && isYesNo(field) {
+				e.Values = funk.Map(e.Values, func(v interface{}) interface{} { return yesNoValue(field, v) }).([]interface{})
+				changed = true
+			}
+			expr = e
+		case clause.AndConditions:
+			if conds, ok := yesNoConditions(s, e.Exprs); ok {
+				expr, changed = clause.AndConditions{Exprs: conds}, true
+			}
+		case clause.OrConditions:
+			if conds, ok := yesNoConditions(s, e.Exprs); ok {
+				expr, changed = clause.OrConditions{Exprs: conds}, true
+			}
+		case clause.NotConditions:
+			if conds, ok := yesNoConditions(s, e.Exprs); ok {
+				expr, changed = clause.NotConditions{Exprs: conds}, true
+			}
+		}
+		converted[idx] = expr
+	}
+	return converted, changed
+}
+
+// columnNameOf is the name of the column of a condition, given as a clause.Column or as a string
+// that may be qualified by its table
+func columnNameOf(column interface{}) string {
+	switch c := column.(type) {
+	case clause.Column:
+		return c.Name
+	case string:
+		return c[strings.LastIndexByte(c, '.')+1:]
+	}
+	return ""
+}
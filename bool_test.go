@@ -0,0 +1,214 @@
+package oracle
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"reflect"
+	"testing"
+
+	"github.com/godror/godror"
+)
+
+func TestScanBool(t *testing.T) {
+	for _, tt := range []struct {
+		value interface{}
+		want  bool
+	}{
+		{true, true},
+		{false, false},
+		{int64(1), true},
+		{int64(0), false},
+		{uint8(1), true},
+		{float64(1), true},
+		{float64(0), false},
+		{godror.Number("1"), true},
+		{godror.Number("0"), false},
+		{"Y", true},
+		{"n", false},
+		{" yes ", true},
+		{"FALSE", false},
+		{"T", true},
+		{[]byte("Y"), true},
+		{[]byte("N"), false},
+	} {
+		got, err := scanBool(tt.value)
+		if err != nil {
+			t.Errorf("failed to scan %#v, got error %v", tt.value, err)
+		} else if got != tt.want {
+			t.Errorf("%#v scanned as %v, want %v", tt.value, got, tt.want)
+		}
+	}
+
+	for _, value := range []interface{}{"maybe", "", []byte("X"), struct{}{}} {
+		if _, err := scanBool(value); err == nil {
+			t.Errorf("scanning %#v should fail", value)
+		}
+	}
+}
+
+func TestBindBool(t *testing.T) {
+	yes := true
+	var nilBool *bool
+	for _, tt := range []struct {
+		value interface{}
+		want  interface{}
+	}{
+		{true, 1},
+		{false, 0},
+		{&yes, 1},
+		{nilBool, nil},
+		{sql.NullBool{Bool: true, Valid: true}, 1},
+		{sql.NullBool{}, nil},
+		{&sql.NullBool{Bool: false, Valid: true}, 0},
+		{"Y", "Y"},
+		{int64(1), int64(1)},
+	} {
+		if got := bindBool(tt.value); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%#v bound as %#v, want %#v", tt.value, got, tt.want)
+		}
+	}
+}
+
+type flagged struct {
+	ID     uint
+	Flag   bool  `gorm:"serializer:yesno"`
+	Maybe  *bool `gorm:"serializer:yesno"`
+	Active bool
+}
+
+func TestYesNo(t *testing.T) {
+	db := dryRun(t, Config{})
+
+	for _, tt := range []struct {
+		name string
+		vars []interface{}
+		sql  string
+		run  func() ([]interface{}, string)
+	}{
+		{
+			name: "create",
+			sql:  "INSERT INTO FLAGGEDS (FLAG,MAYBE,ACTIVE) VALUES (:1,:2,:3) RETURNING ID INTO :4",
+			vars: []interface{}{"Y", nil, 0},
+			run: func() ([]interface{}, string) {
+				tx := db.Create(&flagged{Flag: true})
+				return tx.Statement.Vars[:3], tx.Statement.SQL.String()
+			},
+		},
+		{
+			name: "create map",
+			sql:  "INSERT INTO FLAGGEDS (ACTIVE,FLAG) VALUES (:1,:2) RETURNING ID INTO :3",
+			vars: []interface{}{0, "Y"},
+			run: func() ([]interface{}, string) {
+				tx := db.Model(&flagged{}).Create(map[string]interface{}{"Flag": true, "Active": false})
+				return tx.Statement.Vars[:2], tx.Statement.SQL.String()
+			},
+		},
+		{
+			name: "update struct",
+			sql:  "UPDATE FLAGGEDS SET FLAG=:1,ACTIVE=:2 WHERE ID = :3",
+			vars: []interface{}{"Y", 1, uint(1)},
+			run: func() ([]interface{}, string) {
+				tx := db.Model(&flagged{ID: 1}).Updates(flagged{Flag: true, Active: true})
+				return tx.Statement.Vars, tx.Statement.SQL.String()
+			},
+		},
+		{
+			// the key is matched to the field by its column name in any case
+			name: "update map",
+			sql:  "UPDATE FLAGGEDS SET ACTIVE=:1,flag=:2 WHERE ID = :3",
+			vars: []interface{}{1, "N", uint(1)},
+			run: func() ([]interface{}, string) {
+				tx := db.Model(&flagged{ID: 1}).Updates(map[string]interface{}{"flag": false, "Active": true})
+				return tx.Statement.Vars, tx.Statement.SQL.String()
+			},
+		},
+		{
+			name: "update column",
+			sql:  "UPDATE FLAGGEDS SET FLAG=:1 WHERE ID = :2",
+			vars: []interface{}{"Y", uint(1)},
+			run: func() ([]interface{}, string) {
+				tx := db.Model(&flagged{ID: 1}).Update("Flag", true)
+				return tx.Statement.Vars, tx.Statement.SQL.String()
+			},
+		},
+		{
+			name: "where map",
+			sql:  "SELECT * FROM FLAGGEDS WHERE flag = :1",
+			vars: []interface{}{"Y"},
+			run: func() ([]interface{}, string) {
+				tx := db.Where(map[string]interface{}{"flag": true}).Find(&[]flagged{})
+				return tx.Statement.Vars, tx.Statement.SQL.String()
+			},
+		},
+		{
+			name: "where struct",
+			sql:  "SELECT * FROM FLAGGEDS WHERE FLAGGEDS.FLAG = :1 AND FLAGGEDS.ACTIVE = :2",
+			vars: []interface{}{"Y", 1},
+			run: func() ([]interface{}, string) {
+				tx := db.Where(&flagged{Flag: true, Active: true}).Find(&[]flagged{})
+				return tx.Statement.Vars, tx.Statement.SQL.String()
+			},
+		},
+		{
+			name: "grouped conditions",
+			sql:  "SELECT count(*) FROM FLAGGEDS WHERE (FLAG <> :1 OR MAYBE IN (:2,:3))",
+			vars: []interface{}{"N", "Y", nil},
+			run: func() ([]interface{}, string) {
+				var count int64
+				tx := db.Model(&flagged{}).Where(db.Not(map[string]interface{}{"FLAG": false}).Or(map[string]interface{}{"MAYBE": []interface{}{true, nil}})).Count(&count)
+				return tx.Statement.Vars, tx.Statement.SQL.String()
+			},
+		},
+		{
+			name: "update where",
+			sql:  "UPDATE FLAGGEDS SET ACTIVE=:1 WHERE Flag = :2",
+			vars: []interface{}{1, "N"},
+			run: func() ([]interface{}, string) {
+				tx := db.Model(&flagged{}).Where(map[string]interface{}{"Flag": false}).Update("Active", true)
+				return tx.Statement.Vars, tx.Statement.SQL.String()
+			},
+		},
+		{
+			name: "delete where",
+			sql:  "DELETE FROM FLAGGEDS WHERE FLAGGEDS.FLAG = :1",
+			vars: []interface{}{"Y"},
+			run: func() ([]interface{}, string) {
+				tx := db.Where(&flagged{Flag: true}).Delete(&flagged{})
+				return tx.Statement.Vars, tx.Statement.SQL.String()
+			},
+		},
+		{
+			// the column of a condition written in SQL is unknown, the bool is bound as it is
+			name: "where sql",
+			sql:  "SELECT * FROM FLAGGEDS WHERE FLAG = :1",
+			vars: []interface{}{1},
+			run: func() ([]interface{}, string) {
+				tx := db.Where("FLAG = ?", true).Find(&[]flagged{})
+				return tx.Statement.Vars, tx.Statement.SQL.String()
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			vars, sql := tt.run()
+			if sql != tt.sql {
+				t.Errorf("got SQL\n%s\nwant\n%s", sql, tt.sql)
+			}
+			// serialized fields are bound as valuers
+			for idx, v := range vars {
+				if valuer, ok := v.(driver.Valuer); ok {
+					vars[idx], _ = valuer.Value()
+				}
+			}
+			if !reflect.DeepEqual(vars, tt.vars) {
+				t.Errorf("got vars %#v, want %#v", vars, tt.vars)
+			}
+		})
+	}
+
+	// the model is assigned the bool as it was given
+	model := flagged{ID: 1}
+	db.Model(&model).Updates(map[string]interface{}{"Flag": true})
+	if !model.Flag {
+		t.Errorf("updating the flag by a map should set it on the model")
+	}
+}
@@ -0,0 +1,55 @@
+package clauses
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DualValues selects literal rows from DUAL, glued together with UNION ALL, so that
+// a MERGE can take all of them as its source at once
+type DualValues struct {
+	clause.Values
+}
+
+func (DualValues) Name() string {
+	return "SELECT"
+}
+
+// Build build from clause
+func (v DualValues) Build(builder clause.Builder) {
+	for idx, row := range v.Values.Values {
+		if idx > 0 {
+			builder.WriteString(" UNION ALL SELECT ")
+		}
+
+		for i, value := range row {
+			if i > 0 {
+				builder.WriteByte(',')
+			}
+			builder.AddVar(builder, value)
+
+			// the first select names the columns of the whole union
+			if idx == 0 {
+				builder.WriteString(" AS ")
+				builder.WriteQuoted(v.Columns[i])
+			}
+		}
+		builder.WriteString(fromDual(builder))
+	}
+}
+
+// fromDual returns the FROM clause of the dialector building a SELECT without a table
+func fromDual(builder clause.Builder) string {
+	if stmt, ok := builder.(*gorm.Statement); ok {
+		if dialector, ok := stmt.Dialector.(interface{ FromDual() string }); ok {
+			return dialector.FromDual()
+		}
+	}
+	return " FROM DUAL"
+}
+
+// MergeClause merge dual values clauses
+func (v DualValues) MergeClause(clause *clause.Clause) {
+	clause.Name = v.Name()
+	clause.Expression = v
+}
@@ -0,0 +1,66 @@
+package clauses
+
+import (
+	"strconv"
+
+	"gorm.io/gorm/clause"
+)
+
+// FetchFirst limits a query to its first Rows rows, or Percent percent of them, where WithTies
+// also returns the rows tying with the last one on the ORDER BY, e.g.
+//
+//	db.Order("REVENUE DESC").Clauses(clauses.FetchFirst{Percent: 5, WithTies: true}).Find(&customers)
+//
+// It takes the place of the LIMIT clause, taking over the offset and limit set before it. Before
+// 12c only Rows and Offset can be paged over ROWNUM, Percent and WithTies fail the statement
+type FetchFirst struct {
+	Offset   int
+	Rows     int
+	Percent  float64
+	WithTies bool
+}
+
+func (FetchFirst) Name() string {
+	return "LIMIT"
+}
+
+// Build build from clause
+func (fetch FetchFirst) Build(builder clause.Builder) {
+	if fetch.Offset > 0 {
+		builder.WriteString("OFFSET ")
+		builder.WriteString(strconv.Itoa(fetch.Offset))
+		builder.WriteString(" ROWS")
+	}
+
+	if fetch.Rows > 0 || fetch.Percent > 0 {
+		if fetch.Offset > 0 {
+			builder.WriteByte(' ')
+		}
+		builder.WriteString("FETCH FIRST ")
+		if fetch.Percent > 0 {
+			builder.WriteString(strconv.FormatFloat(fetch.Percent, 'f', -1, 64))
+			builder.WriteString(" PERCENT")
+		} else {
+			builder.WriteString(strconv.Itoa(fetch.Rows))
+		}
+		if fetch.WithTies {
+			builder.WriteString(" ROWS WITH TIES")
+		} else {
+			builder.WriteString(" ROWS ONLY")
+		}
+	}
+}
+
+// MergeClause merge fetch first clauses
+func (fetch FetchFirst) MergeClause(c *clause.Clause) {
+	if limit, ok := c.Expression.(clause.Limit); ok {
+		if fetch.Offset == 0 {
+			fetch.Offset = limit.Offset
+		}
+		if fetch.Rows == 0 && fetch.Percent == 0 && limit.Limit != nil && *limit.Limit > 0 {
+			fetch.Rows = *limit.Limit
+		}
+	}
+	c.Name = fetch.Name()
+	c.Expression = fetch
+}
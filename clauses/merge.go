@@ -4,41 +4,73 @@ import (
 	"gorm.io/gorm/clause"
 )
 
+// Merge is the MERGE INTO ... USING ... ON part of a MERGE statement, the rest of it comes from
+// WhenMatched and WhenNotMatched. The source is Source when set, e.g. a clause.Table or a subquery
+// such as a *gorm.DB, otherwise the subquery built from the Using clauses. Both tables may be
+// referenced in On, the source under Alias, which defaults to MergeDefaultExcludeName.
+//
+//	db.Clauses(
+//		clauses.Merge{
+//			Table:  clause.Table{Name: "ACCOUNTS"},
+//			Source: db.Table("ACCOUNT_UPDATES").Where("BATCH_ID = ?", batchID),
+//			On:     []clause.Expression{clause.Expr{SQL: "ACCOUNTS.ID = excluded.ID"}},
+//		},
+//		clauses.WhenMatched{Set: clause.AssignmentColumns([]string{"BALANCE"})},
+//		clauses.WhenNotMatched{Values: clause.Values{
+//			Columns: []clause.Column{{Name: "ID"}, {Name: "BALANCE"}},
+//			Values:  [][]interface{}{{clause.Column{Table: "excluded", Name: "ID"}, clause.Column{Table: "excluded", Name: "BALANCE"}}},
+//		}},
+//	).Exec("")
 type Merge struct {
-	Table clause.Table
-	Using []clause.Interface
-	On    []clause.Expression
+	Table  clause.Table
+	Using  []clause.Interface
+	Source interface{}
+	Alias  string
+	On     []clause.Expression
 }
 
 func (merge Merge) Name() string {
 	return "MERGE"
 }
 
+// MergeDefaultExcludeName is the alias of the MERGE source, named after the "excluded"
+// table that clause.AssignmentColumns and OnConflict.UpdateAll refer to
 func MergeDefaultExcludeName() string {
-	return "exclude"
+	return "excluded"
 }
 
 // Build build from clause
 func (merge Merge) Build(builder clause.Builder) {
-	clause.Insert{}.Build(builder)
-	builder.WriteString(" USING (")
-	for idx, iface := range merge.Using {
-		if idx > 0 {
+	clause.Insert{Table: merge.Table}.Build(builder)
+	builder.WriteString(" USING ")
+	switch source := merge.Source.(type) {
+	case clause.Table:
+		builder.WriteQuoted(source)
+	case nil:
+		builder.WriteByte('(')
+		for idx, iface := range merge.Using {
+			if idx > 0 {
+				builder.WriteByte(' ')
+			}
+			builder.WriteString(iface.Name())
 			builder.WriteByte(' ')
+			iface.Build(builder)
 		}
-		builder.WriteString(iface.Name())
-		builder.WriteByte(' ')
-		iface.Build(builder)
+		builder.WriteByte(')')
+	default:
+		builder.WriteByte('(')
+		builder.AddVar(builder, source)
+		builder.WriteByte(')')
 	}
-	builder.WriteString(") ")
-	builder.WriteString(MergeDefaultExcludeName())
-	builder.WriteString(" ON (")
-	for idx, on := range merge.On {
-		if idx > 0 {
-			builder.WriteString(", ")
-		}
-		on.Build(builder)
+	builder.WriteByte(' ')
+	if merge.Alias != "" {
+		builder.WriteQuoted(merge.Alias)
+	} else {
+		builder.WriteQuoted(MergeDefaultExcludeName())
 	}
+	// built as a WHERE, which parenthesizes the conditions that would otherwise bind wrongly to AND
+	builder.WriteString(" ON (")
+	clause.Where{Exprs: merge.On}.Build(builder)
 	builder.WriteString(")")
 }
 
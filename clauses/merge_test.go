@@ -0,0 +1,122 @@
+package clauses_test
+
+import (
+	"testing"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/rahmanme/oracle"
+	"github.com/rahmanme/oracle/clauses"
+)
+
+func dryRun(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(oracle.New(oracle.Config{ServerVersion: "19.0.0.0.0"}), &gorm.Config{DryRun: true, DisableAutomaticPing: true, SkipDefaultTransaction: true})
+	if err != nil {
+		t.Fatalf("failed to open dialector, got error %v", err)
+	}
+	return db
+}
+
+func TestMerge(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		clauses []clause.Expression
+		sql     string
+		vars    []interface{}
+	}{
+		{
+			name: "table source",
+			clauses: []clause.Expression{
+				clauses.Merge{
+					Table:  clause.Table{Name: "ACCOUNTS"},
+					Source: clause.Table{Name: "ACCOUNT_UPDATES"},
+					On:     []clause.Expression{clause.Expr{SQL: "ACCOUNTS.ID = excluded.ID"}},
+				},
+				clauses.WhenMatched{Set: clause.AssignmentColumns([]string{"BALANCE"})},
+				clauses.WhenNotMatched{Values: clause.Values{
+					Columns: []clause.Column{{Name: "ID"}, {Name: "BALANCE"}},
+					Values:  [][]interface{}{{clause.Column{Table: "excluded", Name: "ID"}, clause.Column{Table: "excluded", Name: "BALANCE"}}},
+				}},
+			},
+			sql: "MERGE INTO ACCOUNTS USING ACCOUNT_UPDATES excluded ON (ACCOUNTS.ID = excluded.ID) " +
+				"WHEN MATCHED THEN UPDATE SET BALANCE=excluded.BALANCE " +
+				"WHEN NOT MATCHED THEN INSERT (ID,BALANCE) VALUES (excluded.ID,excluded.BALANCE)",
+		},
+		{
+			// conditions joined by OR keep to themselves
+			name: "or conditions",
+			clauses: []clause.Expression{
+				clauses.Merge{
+					Table:  clause.Table{Name: "ACCOUNTS"},
+					Source: clause.Table{Name: "ACCOUNT_UPDATES"},
+					Alias:  "u",
+					On: []clause.Expression{
+						clause.Expr{SQL: "ACCOUNTS.ID = u.ID OR ACCOUNTS.CODE = u.CODE"},
+						clause.Eq{Column: clause.Column{Table: "ACCOUNTS", Name: "ACTIVE"}, Value: 1},
+					},
+				},
+				clauses.WhenMatched{Set: []clause.Assignment{{Column: clause.Column{Name: "BALANCE"}, Value: clause.Column{Table: "u", Name: "BALANCE"}}}},
+			},
+			sql:  "MERGE INTO ACCOUNTS USING ACCOUNT_UPDATES u ON ((ACCOUNTS.ID = u.ID OR ACCOUNTS.CODE = u.CODE) AND ACCOUNTS.ACTIVE = :1) WHEN MATCHED THEN UPDATE SET BALANCE=u.BALANCE",
+			vars: []interface{}{1},
+		},
+		{
+			name: "dual values",
+			clauses: []clause.Expression{
+				clauses.Merge{
+					Table: clause.Table{Name: "ACCOUNTS"},
+					Using: []clause.Interface{clauses.DualValues{Values: clause.Values{
+						Columns: []clause.Column{{Name: "ID"}, {Name: "BALANCE"}},
+						Values:  [][]interface{}{{1, 10}, {2, 20}},
+					}}},
+					On: []clause.Expression{clause.Eq{Column: clause.Column{Table: "ACCOUNTS", Name: "ID"}, Value: clause.Column{Table: "excluded", Name: "ID"}}},
+				},
+				clauses.WhenMatched{
+					Set:    clause.AssignmentColumns([]string{"BALANCE"}),
+					Delete: clause.Where{Exprs: []clause.Expression{clause.Eq{Column: clause.Column{Table: "ACCOUNTS", Name: "BALANCE"}, Value: 0}}},
+				},
+			},
+			sql: "MERGE INTO ACCOUNTS USING (SELECT :1 AS ID,:2 AS BALANCE FROM DUAL UNION ALL SELECT :3,:4 FROM DUAL) excluded ON (ACCOUNTS.ID = excluded.ID) " +
+				"WHEN MATCHED THEN UPDATE SET BALANCE=excluded.BALANCE DELETE WHERE ACCOUNTS.BALANCE = :5",
+			vars: []interface{}{1, 10, 2, 20, 0},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			tx := dryRun(t).Clauses(tt.clauses...).Exec("")
+			if tx.Error != nil {
+				t.Fatalf("failed to build MERGE, got error %v", tx.Error)
+			}
+			if sql := tx.Statement.SQL.String(); sql != tt.sql {
+				t.Errorf("got SQL\n%s\nwant\n%s", sql, tt.sql)
+			}
+			if len(tx.Statement.Vars) != len(tt.vars) {
+				t.Fatalf("got vars %v, want %v", tx.Statement.Vars, tt.vars)
+			}
+			for idx, v := range tx.Statement.Vars {
+				if v != tt.vars[idx] {
+					t.Errorf("got vars %v, want %v", tx.Statement.Vars, tt.vars)
+				}
+			}
+		})
+	}
+}
+
+func TestWhenNotMatchedRows(t *testing.T) {
+	// a MERGE inserts one row per source row, more rows of values are an error rather than a panic
+	tx := dryRun(t).Clauses(
+		clauses.Merge{
+			Table:  clause.Table{Name: "ACCOUNTS"},
+			Source: clause.Table{Name: "ACCOUNT_UPDATES"},
+			On:     []clause.Expression{clause.Expr{SQL: "ACCOUNTS.ID = excluded.ID"}},
+		},
+		clauses.WhenNotMatched{Values: clause.Values{
+			Columns: []clause.Column{{Name: "ID"}},
+			Values:  [][]interface{}{{1}, {2}},
+		}},
+	).Exec("")
+	if tx.Error == nil {
+		t.Errorf("inserting more than one row of values should fail")
+	}
+}
@@ -4,7 +4,41 @@ import (
 	"gorm.io/gorm/clause"
 )
 
+// ReturningInto hands the Variables of the affected rows back through the out binds in Into,
+// one sql.Out per variable, whose Dest is a slice when more than one row may come back
 type ReturningInto struct {
-	Variables []clause.Column
-	Into      []*clause.Values
+	Variables   []clause.Column
+	Into        []interface{}
+	BulkCollect bool
+}
+
+func (ReturningInto) Name() string {
+	return "RETURNING"
+}
+
+// Build build from clause
+func (returning ReturningInto) Build(builder clause.Builder) {
+	for idx, column := range returning.Variables {
+		if idx > 0 {
+			builder.WriteByte(',')
+		}
+		builder.WriteQuoted(column)
+	}
+
+	if returning.BulkCollect {
+		builder.WriteString(" BULK COLLECT")
+	}
+	builder.WriteString(" INTO ")
+	for idx, into := range returning.Into {
+		if idx > 0 {
+			builder.WriteByte(',')
+		}
+		builder.AddVar(builder, into)
+	}
+}
+
+// MergeClause merge returning into clauses
+func (returning ReturningInto) MergeClause(clause *clause.Clause) {
+	clause.Name = returning.Name()
+	clause.Expression = returning
 }
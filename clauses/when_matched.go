@@ -4,6 +4,9 @@ import (
 	"gorm.io/gorm/clause"
 )
 
+// WhenMatched updates the matched rows with Set, limited to the rows satisfying Where, and then
+// deletes those of them satisfying Delete. Oracle has no matched branch without an update, so
+// nothing is written when Set is empty.
 type WhenMatched struct {
 	clause.Set
 	Where, Delete clause.Where
@@ -15,25 +18,31 @@ func (w WhenMatched) Name() string {
 
 func (w WhenMatched) Build(builder clause.Builder) {
 	if len(w.Set) > 0 {
-		builder.WriteString(" THEN")
-		builder.WriteString(" UPDATE ")
 		builder.WriteString(w.Name())
+		builder.WriteString(" THEN UPDATE ")
+		builder.WriteString(w.Set.Name())
 		builder.WriteByte(' ')
-		w.Build(builder)
-
-		buildWhere := func(where clause.Where) {
-			builder.WriteString(where.Name())
-			builder.WriteByte(' ')
-			where.Build(builder)
-		}
+		w.Set.Build(builder)
 
 		if len(w.Where.Exprs) > 0 {
-			buildWhere(w.Where)
+			builder.WriteByte(' ')
+			builder.WriteString(w.Where.Name())
+			builder.WriteByte(' ')
+			w.Where.Build(builder)
 		}
 
 		if len(w.Delete.Exprs) > 0 {
 			builder.WriteString(" DELETE ")
-			buildWhere(w.Delete)
+			builder.WriteString(w.Delete.Name())
+			builder.WriteByte(' ')
+			w.Delete.Build(builder)
 		}
 	}
 }
+
+// MergeClause merge when matched clauses, the clause name is written by Build as it
+// depends on whether there is anything to update
+func (w WhenMatched) MergeClause(clause *clause.Clause) {
+	clause.Name = ""
+	clause.Expression = w
+}
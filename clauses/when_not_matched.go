@@ -1,9 +1,14 @@
 package clauses
 
 import (
+	"errors"
+
 	"gorm.io/gorm/clause"
 )
 
+// WhenNotMatched inserts the single row of Values for every source row without a match,
+// limited to the source rows satisfying Where. Its values usually refer to the columns of
+// the source, e.g. clause.Column{Table: MergeDefaultExcludeName(), Name: "ID"}.
 type WhenNotMatched struct {
 	clause.Values
 	Where clause.Where
@@ -16,17 +21,26 @@ func (w WhenNotMatched) Name() string {
 func (w WhenNotMatched) Build(builder clause.Builder) {
 	if len(w.Columns) > 0 {
 		if len(w.Values.Values) != 1 {
-			panic("cannot insert more than one rows due to Oracle SQL language restriction")
+			builder.AddError(errors.New("cannot insert more than one rows due to Oracle SQL language restriction"))
+			return
 		}
 
-		builder.WriteString(" THEN")
-		builder.WriteString(" INSERT ")
-		w.Build(builder)
+		builder.WriteString(w.Name())
+		builder.WriteString(" THEN INSERT ")
+		w.Values.Build(builder)
 
 		if len(w.Where.Exprs) > 0 {
+			builder.WriteByte(' ')
 			builder.WriteString(w.Where.Name())
 			builder.WriteByte(' ')
 			w.Where.Build(builder)
 		}
 	}
 }
+
+// MergeClause merge when not matched clauses, the clause name is written by Build
+// as it depends on whether there is anything to insert
+func (w WhenNotMatched) MergeClause(clause *clause.Clause) {
+	clause.Name = ""
+	clause.Expression = w
+}
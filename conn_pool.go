@@ -0,0 +1,111 @@
+package oracle
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// connPool is the connection pool of the dialector, beginning transactions with the options oracle
+// takes and running statements outside of them again as the retry policy says
+type connPool struct {
+	*sql.DB
+	retry *RetryPolicy
+}
+
+func (db *connPool) GetDBConn() (*sql.DB, error) {
+	return db.DB, nil
+}
+
+func (db *connPool) ExecContext(ctx context.Context, query string, args ...interface{}) (result sql.Result, err error) {
+	if db.retry == nil {
+		return db.DB.ExecContext(ctx, query, args...)
+	}
+	err = db.retry.run(ctx, false, func() (err error) {
+		result, err = db.DB.ExecContext(ctx, query, args...)
+		return
+	})
+	return
+}
+
+func (db *connPool) QueryContext(ctx context.Context, query string, args ...interface{}) (rows *sql.Rows, err error) {
+	if db.retry == nil {
+		return db.DB.QueryContext(ctx, query, args...)
+	}
+	err = db.retry.run(ctx, true, func() (err error) {
+		rows, err = db.DB.QueryContext(ctx, query, args...)
+		return
+	})
+	return
+}
+
+func (db *connPool) QueryRowContext(ctx context.Context, query string, args ...interface{}) (row *sql.Row) {
+	if db.retry == nil {
+		return db.DB.QueryRowContext(ctx, query, args...)
+	}
+	db.retry.run(ctx, true, func() error {
+		row = db.DB.QueryRowContext(ctx, query, args...)
+		return row.Err()
+	})
+	return
+}
+
+// BeginTx begins a transaction that is READ ONLY as opts say. Oracle knows no other isolation levels
+// than READ COMMITTED and SERIALIZABLE, and a serializable transaction needs a session of its own, which
+// Transaction sets up
+func (db *connPool) BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error) {
+	if opts == nil {
+		return db.DB.BeginTx(ctx, nil)
+	}
+
+	switch opts.Isolation {
+	case sql.LevelDefault, sql.LevelReadCommitted:
+	case sql.LevelSerializable:
+		// a read only transaction reads the data as of its start, which makes it serializable already
+		if !opts.ReadOnly {
+			return nil, fmt.Errorf("%w: serializable transactions begin by oracle.Transaction", gorm.ErrNotImplemented)
+		}
+	default:
+		return nil, fmt.Errorf("%w: isolation level %s", gorm.ErrNotImplemented, opts.Isolation)
+	}
+	return db.DB.BeginTx(ctx, &sql.TxOptions{ReadOnly: opts.ReadOnly})
+}
+
+// isSerializable reports whether opts ask for a serializable transaction that is not read only
+func isSerializable(opts []*sql.TxOptions) bool {
+	return len(opts) > 0 && opts[0] != nil && opts[0].Isolation == sql.LevelSerializable && !opts[0].ReadOnly
+}
+
+// serializable runs fc on a session of its own made serializable while fc runs. godror sets a
+// transaction READ WRITE as its first statement, after which no SET TRANSACTION can follow, so the
+// isolation level is the session's instead
+func serializable(db *gorm.DB, fc func(tx *gorm.DB) error) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	conn, err := sqlDB.Conn(db.Statement.Context)
+	if err != nil {
+		return err
+	}
+	defer release(conn)
+	if _, err = conn.ExecContext(db.Statement.Context, "ALTER SESSION SET ISOLATION_LEVEL = SERIALIZABLE"); err != nil {
+		return err
+	}
+
+	tx := db.Session(&gorm.Session{Context: db.Statement.Context})
+	tx.Statement.ConnPool = conn
+	return fc(tx)
+}
+
+// release sets the session back to READ COMMITTED and returns it to the pool, a session failing to
+// do so is discarded instead
+func release(conn *sql.Conn) {
+	if _, err := conn.ExecContext(context.Background(), "ALTER SESSION SET ISOLATION_LEVEL = READ COMMITTED"); err != nil {
+		conn.Raw(func(interface{}) error { return driver.ErrBadConn })
+	}
+	conn.Close()
+}
@@ -0,0 +1,46 @@
+package oracle
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+// gorm only begins transactions on pools returning a *sql.Tx, prepared statements included
+var (
+	_ gorm.TxBeginner     = (*connPool)(nil)
+	_ gorm.GetDBConnector = (*connPool)(nil)
+)
+
+func TestConnPoolBeginTxOptions(t *testing.T) {
+	pool := &connPool{}
+	for _, opts := range []*sql.TxOptions{
+		{Isolation: sql.LevelSerializable},
+		{Isolation: sql.LevelRepeatableRead},
+		{Isolation: sql.LevelReadUncommitted, ReadOnly: true},
+	} {
+		if _, err := pool.BeginTx(context.Background(), opts); !errors.Is(err, gorm.ErrNotImplemented) {
+			t.Errorf("%+v should fail with gorm.ErrNotImplemented, got error %v", opts, err)
+		}
+	}
+}
+
+func TestIsSerializable(t *testing.T) {
+	for _, tt := range []struct {
+		opts []*sql.TxOptions
+		want bool
+	}{
+		{nil, false},
+		{[]*sql.TxOptions{nil}, false},
+		{[]*sql.TxOptions{{Isolation: sql.LevelSerializable}}, true},
+		{[]*sql.TxOptions{{Isolation: sql.LevelSerializable, ReadOnly: true}}, false},
+		{[]*sql.TxOptions{{Isolation: sql.LevelReadCommitted}}, false},
+	} {
+		if got := isSerializable(tt.opts); got != tt.want {
+			t.Errorf("isSerializable(%+v) = %v, want %v", tt.opts, got, tt.want)
+		}
+	}
+}
@@ -1,15 +1,21 @@
 package oracle
 
 import (
-	"bytes"
+	"context"
 	"database/sql"
+	"errors"
+	"fmt"
 	"reflect"
+	"strconv"
+	"strings"
 
+	"github.com/godror/godror"
 	"github.com/thoas/go-funk"
 	"gorm.io/gorm"
 	"gorm.io/gorm/callbacks"
 	"gorm.io/gorm/clause"
 	gormSchema "gorm.io/gorm/schema"
+	"gorm.io/gorm/utils"
 
 	"github.com/rahmanme/oracle/clauses"
 )
@@ -17,13 +23,18 @@ import (
 func Create(db *gorm.DB) {
 	stmt := db.Statement
 	schema := stmt.Schema
-	boundVars := make(map[string]int)
+
+	var (
+		arrayVars   []interface{}
+		useArrayDML bool
+		into        []interface{}
+	)
 
 	if stmt == nil || schema == nil {
 		return
 	}
 
-	hasDefaultValues := len(schema.FieldsWithDefaultDBValue) > 0
+	hasDefaultValues := len(returningFields(schema)) > 0
 
 	if !stmt.Unscoped {
 		for _, c := range schema.CreateClauses {
@@ -33,120 +44,551 @@ func Create(db *gorm.DB) {
 
 	if stmt.SQL.String() == "" {
 		values := callbacks.ConvertToCreateValues(stmt)
+		// an empty slice has nothing to insert, ConvertToCreateValues has recorded gorm.ErrEmptySlice
+		if len(values.Values) == 0 {
+			return
+		}
+		// the values of maps come without the serializer of their field, yesno bools are written here
+		for idx, column := range values.Columns {
+			if field := lookUpColumn(schema, column.Name); field != nil && isYesNo(field) {
+				for _, vals := range values.Values {
+					vals[idx] = yesNoValue(field, vals[idx])
+				}
+			}
+		}
 		onConflict, hasConflict := stmt.Clauses["ON CONFLICT"].Expression.(clause.OnConflict)
-		// are all columns in value the primary fields in schema only?
-		if hasConflict && funk.Contains(
+		// the conflict target defaults to the primary key, and MERGE can only match on columns its source provides,
+		// the columns of a given target are named by their fields or columns in any case like everywhere else
+		conflictColumns := funk.Map(onConflict.Columns, func(column clause.Column) clause.Column {
+			if field := lookUpColumn(schema, column.Name); field != nil {
+				return clause.Column{Name: field.DBName}
+			}
+			return column
+		}).([]clause.Column)
+		if len(conflictColumns) == 0 {
+			conflictColumns = funk.Map(schema.PrimaryFields, func(field *gormSchema.Field) clause.Column {
+				return clause.Column{Name: field.DBName}
+			}).([]clause.Column)
+		}
+		conflictNames := funk.Map(conflictColumns, func(c clause.Column) string { return c.Name }).([]string)
+		upsert := hasConflict && len(conflictColumns) > 0 && funk.Subset(
+			conflictNames,
 			funk.Map(values.Columns, func(c clause.Column) string { return c.Name }),
-			funk.Map(schema.PrimaryFields, func(field *gormSchema.Field) string { return field.DBName }),
-		) {
-			stmt.AddClauseIfNotExists(clauses.Merge{
-				Using: []clause.Interface{
-					clause.Select{
-						Columns: funk.Map(values.Columns, func(column clause.Column) clause.Column {
-							// HACK: I can not come up with a better alternative for now
-							// I want to add a value to the list of variable and then capture the bind variable position as well
-							buf := bytes.NewBufferString("")
-							stmt.Vars = append(stmt.Vars, values.Values[0][funk.IndexOf(values.Columns, column)])
-							stmt.BindVarTo(buf, stmt, nil)
-
-							column.Alias = column.Name
-							// then the captured bind var will be the name
-							column.Name = buf.String()
-							return column
-						}).([]clause.Column),
-					},
-					clause.From{
-						Tables: []clause.Table{{Name: db.Dialector.(Dialector).DummyTableName()}},
-					},
-				},
-				On: funk.Map(schema.PrimaryFields, func(field *gormSchema.Field) clause.Expression {
-					return clause.Eq{
-						Column: clause.Column{Table: stmt.Table, Name: field.DBName},
-						Value:  clause.Column{Table: clauses.MergeDefaultExcludeName(), Name: field.DBName},
+		)
+		// a primary key left to the database conflicts with nothing and the rows are just inserted,
+		// any other target left out of the insert could never be matched
+		if hasConflict && !upsert {
+			for _, name := range conflictNames {
+				if field := lookUpColumn(schema, name); columnIndex(values.Columns, name) < 0 && (field == nil || !field.PrimaryKey) {
+					db.AddError(fmt.Errorf("%w: conflict column %s is not inserted", gorm.ErrInvalidField, name))
+					return
+				}
+			}
+		}
+		var groups []mergeGroup
+		if upsert {
+			// rows of the source without a value for a field drawn from a sequence leave it NULL there
+			// and take the next value when they are inserted, conflict keys are left alone
+			for _, field := range sequenceFields(schema) {
+				if idx := columnIndex(values.Columns, field.DBName); idx >= 0 && !funk.ContainsString(conflictNames, field.DBName) {
+					for _, vals := range values.Values {
+						if isZeroValue(vals[idx]) {
+							vals[idx] = nil
+						}
 					}
-				}).([]clause.Expression),
-			})
-			stmt.AddClauseIfNotExists(clauses.WhenMatched{Set: onConflict.DoUpdates})
-			stmt.AddClauseIfNotExists(clauses.WhenNotMatched{Values: values})
+				}
+			}
 
-			stmt.Build("MERGE", "WHEN MATCHED", "WHEN NOT MATCHED")
+			// DEFAULT has no place in a SELECT either, but inserting the NULL left in its place would
+			// override the default, e.g. of an identity, so rows are merged in groups by the columns
+			// they leave to the database, each group leaving them out of its insert
+			groups = groupDefaults(values)
+			buildMerge(stmt, onConflict, conflictColumns, groups[0])
 		} else {
 			stmt.AddClauseIfNotExists(clause.Insert{Table: clause.Table{Name: stmt.Table}})
-			stmt.AddClause(clause.Values{Columns: values.Columns, Values: [][]interface{}{values.Values[0]}})
-			if hasDefaultValues {
-				stmt.AddClauseIfNotExists(clause.Returning{
-					Columns: funk.Map(schema.FieldsWithDefaultDBValue, func(field *gormSchema.Field) clause.Column {
-						return clause.Column{Name: field.DBName}
-					}).([]clause.Column),
-				})
-			}
-			stmt.Build("INSERT", "VALUES", "RETURNING")
-			if hasDefaultValues {
-				stmt.WriteString(" INTO ")
-				for idx, field := range schema.FieldsWithDefaultDBValue {
-					if idx > 0 {
-						stmt.WriteByte(',')
+
+			// rows without a value for a field drawn from a sequence take its next one
+			for _, field := range sequenceFields(schema) {
+				idx := columnIndex(values.Columns, field.DBName)
+				if idx < 0 {
+					values.Columns = append(values.Columns, clause.Column{Name: field.DBName})
+					for i := range values.Values {
+						values.Values[i] = append(values.Values[i], nextVal(stmt, sequenceOf(field)))
 					}
-					boundVars[field.Name] = len(stmt.Vars)
-					stmt.AddVar(stmt, sql.Out{Dest: reflect.New(field.FieldType).Interface()})
+					continue
+				}
+				for _, vals := range values.Values {
+					if isZeroValue(vals[idx]) {
+						vals[idx] = nextVal(stmt, sequenceOf(field))
+					}
+				}
+			}
+
+			// more than one row can be sent as a single array DML execution by binding each column as a slice,
+			// but godror only hands back the returned values of the first row, so when we need them we wrap
+			// the insert in a FORALL block and bulk collect them into PL/SQL arrays instead
+			row := values.Values[0]
+			if len(values.Values) > 1 {
+				row, arrayVars, useArrayDML = arrayBindVars(values)
+				if !useArrayDML {
+					row = values.Values[0]
 				}
 			}
+
+			if hasDefaultValues {
+				into = addReturning(stmt, len(values.Values), useArrayDML)
+			}
+
+			if useArrayDML && hasDefaultValues {
+				buildForall(stmt, values.Columns, row, len(values.Values))
+			} else {
+				buildInsert(stmt, values.Columns, row)
+			}
 		}
 
-		if !db.DryRun {
-			for idx, vals := range values.Values {
-				// HACK HACK: replace values one by one, assuming its value layout will be the same all the time, i.e. aligned
-				for idx, val := range vals {
-					switch v := val.(type) {
-					case bool:
-						if v {
-							val = 1
-						} else {
-							val = 0
+		if !db.DryRun && db.Error == nil {
+			if upsert {
+				// a MERGE carries all the rows of a group, but the default values are read back afterwards,
+				// so all of them share the transaction to see the same rows
+				db.AddError(atomically(db, func() error {
+					for idx, group := range groups {
+						if idx > 0 {
+							buildMerge(stmt, onConflict, conflictColumns, group)
+						}
+						result, err := stmt.ConnPool.ExecContext(stmt.Context, stmt.SQL.String(), stmt.Vars...)
+						if err != nil {
+							return err
+						}
+						rowsAffected, _ := result.RowsAffected()
+						db.RowsAffected += rowsAffected
+					}
+
+					if hasDefaultValues {
+						return selectDefaultValues(db, conflictColumns)
+					}
+					return nil
+				}))
+			} else if len(values.Values) > 1 {
+				// a failed row must not leave the rows before it behind, so the whole slice goes in or nothing does
+				db.AddError(atomically(db, func() error {
+					if useArrayDML {
+						copy(stmt.Vars, arrayVars)
+						err := createArray(db, len(values.Values), into)
+						var oraErr *godror.OraErr
+						if err == nil || !hasDefaultValues || !errors.As(err, &oraErr) {
+							return err
 						}
+
+						// the FORALL block fails as a whole, rolling back what it inserted without telling
+						// which row failed, so the rows go in one by one to find it
+						db.RowsAffected = 0
+						into = addReturning(stmt, len(values.Values), false)
+						buildInsert(stmt, values.Columns, values.Values[0])
 					}
+					return createRows(db, values, into)
+				}))
+			} else {
+				db.AddError(createRows(db, values, into))
+			}
+		}
+	}
+}
+
+// mergeGroup holds upserted rows leaving the same columns to their default values
+type mergeGroup struct {
+	clause.Values
+	defaults []string
+}
 
-					stmt.Vars[idx] = val
+// groupDefaults groups the rows of values by the columns they set to DEFAULT, in the order the groups
+// first appear, DEFAULT is replaced by NULL as the rows are selected in the source of a MERGE
+func groupDefaults(values clause.Values) (groups []mergeGroup) {
+	indexes := map[string]int{}
+	for _, vals := range values.Values {
+		var defaults []string
+		for idx, v := range vals {
+			if isDefaultValue(v) {
+				vals[idx] = nil
+				defaults = append(defaults, values.Columns[idx].Name)
+			}
+		}
+
+		key := strings.Join(defaults, ",")
+		if _, ok := indexes[key]; !ok {
+			indexes[key] = len(groups)
+			groups = append(groups, mergeGroup{Values: clause.Values{Columns: values.Columns}, defaults: defaults})
+		}
+		groups[indexes[key]].Values.Values = append(groups[indexes[key]].Values.Values, vals)
+	}
+	return
+}
+
+// buildMerge writes the MERGE upserting the rows of group, selected from DUAL and unioned together as its
+// source, the columns the group leaves to their default values are neither inserted nor updated
+func buildMerge(stmt *gorm.Statement, onConflict clause.OnConflict, conflictColumns []clause.Column, group mergeGroup) {
+	schema := stmt.Schema
+	conflictNames := funk.Map(conflictColumns, func(c clause.Column) string { return c.Name }).([]string)
+
+	stmt.SQL.Reset()
+	stmt.Vars = nil
+	stmt.AddClause(clauses.Merge{
+		Using: []clause.Interface{clauses.DualValues{Values: group.Values}},
+		On: funk.Map(conflictColumns, func(column clause.Column) clause.Expression {
+			return clause.Eq{
+				Column: clause.Column{Table: stmt.Table, Name: column.Name},
+				Value:  clause.Column{Table: clauses.MergeDefaultExcludeName(), Name: column.Name},
+			}
+		}).([]clause.Expression),
+	})
+
+	// oracle refuses to update the columns referenced in the ON condition (ORA-38104)
+	// and UpdateAll leaves fields drawn from a sequence alone, as it does for those with a default value
+	doUpdates := funk.Filter(onConflict.DoUpdates, func(assignment clause.Assignment) bool {
+		if onConflict.UpdateAll && funk.Contains(sequenceFields(schema), func(field *gormSchema.Field) bool { return field.DBName == assignment.Column.Name }) {
+			return false
+		}
+		return !funk.ContainsString(conflictNames, assignment.Column.Name) && !funk.ContainsString(group.defaults, assignment.Column.Name)
+	}).([]clause.Assignment)
+	if !onConflict.DoNothing && len(doUpdates) > 0 {
+		stmt.AddClause(clauses.WhenMatched{Set: doUpdates, Where: onConflict.Where})
+	} else {
+		delete(stmt.Clauses, "WHEN MATCHED")
+	}
+
+	// NEXTVAL is not allowed in the UNION of the source, so rows drawing their key from a sequence
+	// take the next value when they are inserted
+	var (
+		insertColumns []clause.Column
+		insertValues  []interface{}
+	)
+	for _, column := range group.Columns {
+		if !funk.ContainsString(group.defaults, column.Name) {
+			insertColumns = append(insertColumns, column)
+			insertValues = append(insertValues, clause.Column{Table: clauses.MergeDefaultExcludeName(), Name: column.Name})
+		}
+	}
+	for _, field := range sequenceFields(schema) {
+		if funk.ContainsString(conflictNames, field.DBName) {
+			continue
+		}
+		if idx := columnIndex(insertColumns, field.DBName); idx >= 0 {
+			insertValues[idx] = clause.Expr{SQL: "NVL(?, " + nextVal(stmt, sequenceOf(field)).SQL + ")", Vars: []interface{}{insertValues[idx]}}
+		} else {
+			insertColumns = append(insertColumns, clause.Column{Name: field.DBName})
+			insertValues = append(insertValues, nextVal(stmt, sequenceOf(field)))
+		}
+	}
+	stmt.AddClause(clauses.WhenNotMatched{Values: clause.Values{
+		Columns: insertColumns,
+		Values:  [][]interface{}{insertValues},
+	}})
+
+	stmt.Build("MERGE", "WHEN MATCHED", "WHEN NOT MATCHED")
+}
+
+// createArray executes the statement prepared with array binds once for the whole batch, into holds
+// the out binds of the default valued fields
+func createArray(db *gorm.DB, rows int, into []interface{}) error {
+	stmt := db.Statement
+	schema := stmt.Schema
+	hasDefaultValues := len(returningFields(schema)) > 0
+
+	vars := stmt.Vars
+	if hasDefaultValues {
+		vars = append(append(make([]interface{}, 0, len(vars)+2), vars...), godror.PlSQLArrays, godror.ArraySize(rows))
+	}
+
+	result, err := stmt.ConnPool.ExecContext(stmt.Context, stmt.SQL.String(), vars...)
+	if err != nil {
+		var oraErr *godror.OraErr
+		if !hasDefaultValues && errors.As(err, &oraErr) {
+			// for array DML oracle reports the offending row as the error offset
+			return &RowError{Index: oraErr.Offset(), Err: err}
+		}
+		return err
+	}
+	db.RowsAffected, _ = result.RowsAffected()
+
+	if hasDefaultValues {
+		// the FORALL block reports itself as one call, every row of the batch made it in though
+		db.RowsAffected = int64(rows)
+
+		// bind the collected arrays back to the reflected value, one element per row
+		for i, field := range returningFields(schema) {
+			returned := reflect.ValueOf(into[i].(sql.Out).Dest).Elem()
+			for idx := 0; idx < returned.Len() && idx < stmt.ReflectValue.Len(); idx++ {
+				if err = setFieldValue(stmt.Context, field, stmt.ReflectValue.Index(idx), returned.Index(idx).Interface()); err != nil {
+					db.AddError(err)
 				}
-				// and then we insert each row one by one then put the returning values back (i.e. last return id => smart insert)
-				// we keep track of the index so that the sub-reflected value is also correct
+			}
+		}
+	}
+	return nil
+}
+
+// addReturning adds the RETURNING INTO clause of the default valued fields, collecting them into
+// arrays of rows elements in bulk, and returns the out binds it reads them into
+func addReturning(stmt *gorm.Statement, rows int, bulk bool) []interface{} {
+	returning := clauses.ReturningInto{BulkCollect: bulk}
+	for _, field := range returningFields(stmt.Schema) {
+		returning.Variables = append(returning.Variables, clause.Column{Name: field.DBName})
+		if bulk {
+			returning.Into = append(returning.Into, sql.Out{Dest: outArrayOf(field, rows)})
+		} else {
+			returning.Into = append(returning.Into, sql.Out{Dest: reflect.New(field.FieldType).Interface()})
+		}
+	}
+	stmt.AddClause(returning)
+	return returning.Into
+}
 
-				// BIG BUG: what if any of the transactions failed? some result might already be inserted that oracle is so
-				// sneaky that some transaction inserts will exceed the buffer and so will be pushed at unknown point,
-				// resulting in dangling row entries, so we might need to delete them if an error happens
+// buildForall writes the INSERT of a batch bound as arrays as a FORALL block, indexing the placeholders
+// of the bound columns by the loop while the columns rendered inline are written as they are
+func buildForall(stmt *gorm.Statement, columns []clause.Column, row []interface{}, rows int) {
+	stmt.SQL.Reset()
+	stmt.Vars = nil
+	stmt.WriteString("BEGIN FORALL i IN 1 .. ")
+	stmt.WriteString(strconv.Itoa(rows))
+	stmt.WriteByte(' ')
+	stmt.Build("INSERT")
 
-				switch result, err := stmt.ConnPool.ExecContext(stmt.Context, stmt.SQL.String(), stmt.Vars...); err {
-				case nil: // success
-					db.RowsAffected, _ = result.RowsAffected()
+	stmt.WriteString(" (")
+	for idx, column := range columns {
+		if idx > 0 {
+			stmt.WriteByte(',')
+		}
+		stmt.WriteQuoted(column)
+	}
+	stmt.WriteString(") VALUES (")
+	for idx, value := range row {
+		if idx > 0 {
+			stmt.WriteByte(',')
+		}
+		stmt.AddVar(stmt, value)
+		if !isExpression(value) {
+			stmt.WriteString("(i)")
+		}
+	}
+	stmt.WriteString(") ")
+	stmt.Build("RETURNING")
+	stmt.WriteString("; END;")
+}
 
-					insertTo := stmt.ReflectValue
-					switch insertTo.Kind() {
-					case reflect.Slice, reflect.Array:
-						insertTo = insertTo.Index(idx)
-					}
+// buildInsert writes the INSERT statement of a single row, with its RETURNING clause when it has one
+func buildInsert(stmt *gorm.Statement, columns []clause.Column, row []interface{}) {
+	stmt.SQL.Reset()
+	stmt.Vars = nil
+	stmt.AddClause(clause.Values{Columns: columns, Values: [][]interface{}{row}})
+	stmt.Build("INSERT", "VALUES")
+	if _, ok := stmt.Clauses["RETURNING"]; ok {
+		stmt.WriteByte(' ')
+		stmt.Build("RETURNING")
+	}
+}
 
-					if hasDefaultValues {
-						// bind returning value back to reflected value in the respective fields
-						funk.ForEach(
-							funk.Filter(schema.FieldsWithDefaultDBValue, func(field *gormSchema.Field) bool {
-								return funk.Contains(boundVars, field.Name)
-							}),
-							func(field *gormSchema.Field) {
-								switch insertTo.Kind() {
-								case reflect.Struct:
-									if err = field.Set(stmt.Context, insertTo, stmt.Vars[boundVars[field.Name]].(sql.Out).Dest); err != nil {
-										db.AddError(err)
-									}
-								case reflect.Map:
-									// todo 设置id的值
-								}
-							},
-						)
-					}
-				default: // failure
+// createRows executes the statement once per row, into holds the out binds of the default valued fields
+func createRows(db *gorm.DB, values clause.Values, into []interface{}) error {
+	stmt := db.Statement
+	schema := stmt.Schema
+	hasDefaultValues := len(returningFields(schema)) > 0
+
+	for idx, vals := range values.Values {
+		// a row may hold SQL expressions or sub queries binding vars of their own, so every row
+		// builds its own statement rather than reusing the binds of the first one
+		if idx > 0 {
+			buildInsert(stmt, values.Columns, vals)
+		}
+		// and then we insert each row one by one then put the returning values back (i.e. last return id => smart insert)
+		// we keep track of the index so that the sub-reflected value is also correct
+		result, err := stmt.ConnPool.ExecContext(stmt.Context, stmt.SQL.String(), stmt.Vars...)
+		if err != nil {
+			if len(values.Values) > 1 {
+				return &RowError{Index: idx, Err: err}
+			}
+			return err
+		}
+		rowsAffected, _ := result.RowsAffected()
+		db.RowsAffected += rowsAffected
+
+		insertTo := stmt.ReflectValue
+		switch insertTo.Kind() {
+		case reflect.Slice, reflect.Array:
+			insertTo = insertTo.Index(idx)
+		}
+
+		if hasDefaultValues {
+			// bind returning value back to reflected value in the respective fields
+			for i, field := range returningFields(schema) {
+				if err = setFieldValue(stmt.Context, field, insertTo, into[i].(sql.Out).Dest); err != nil {
 					db.AddError(err)
 				}
 			}
 		}
 	}
+	return nil
+}
+
+// selectDefaultValues reads the default valued fields of upserted rows back by their conflict key,
+// as MERGE has no RETURNING clause to hand them over like INSERT does
+func selectDefaultValues(db *gorm.DB, conflictColumns []clause.Column) error {
+	stmt := db.Statement
+	schema := stmt.Schema
+
+	keyFields := make([]*gormSchema.Field, 0, len(conflictColumns))
+	for _, column := range conflictColumns {
+		field := schema.LookUpField(column.Name)
+		if field == nil {
+			return nil
+		}
+		keyFields = append(keyFields, field)
+	}
+
+	upserted, keyValues := identityValuesMap(stmt.Context, stmt.ReflectValue, keyFields)
+	if len(keyValues) == 0 {
+		return nil
+	}
+	keyDBNames := funk.Map(keyFields, func(field *gormSchema.Field) string { return field.DBName }).([]string)
+
+	// oracle allows no more than 1000 expressions in an IN list (ORA-01795)
+	for _, chunk := range funk.Chunk(keyValues, 1000).([][][]interface{}) {
+		column, values := gormSchema.ToQueryValues(stmt.Table, keyDBNames, chunk)
+		results := reflect.New(reflect.SliceOf(schema.ModelType))
+		if err := db.Session(&gorm.Session{NewDB: true}).Unscoped().Table(stmt.Table).
+			Where(clause.IN{Column: column, Values: values}).Find(results.Interface()).Error; err != nil {
+			return err
+		}
+
+		for idx := 0; idx < results.Elem().Len(); idx++ {
+			result := results.Elem().Index(idx)
+			key := make([]interface{}, len(keyFields))
+			for i, field := range keyFields {
+				key[i], _ = field.ValueOf(stmt.Context, result)
+			}
+
+			for _, insertTo := range upserted[utils.ToStringKey(key...)] {
+				for _, field := range returningFields(schema) {
+					value, _ := field.ValueOf(stmt.Context, result)
+					db.AddError(setFieldValue(stmt.Context, field, insertTo, value))
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// identityValuesMap is schema.GetIdentityFieldValuesMap for structs as well as maps
+func identityValuesMap(ctx context.Context, reflectValue reflect.Value, fields []*gormSchema.Field) (map[string][]reflect.Value, [][]interface{}) {
+	var (
+		results     [][]interface{}
+		dataResults = map[string][]reflect.Value{}
+		elems       = []reflect.Value{reflectValue}
+	)
+
+	switch reflectValue.Kind() {
+	case reflect.Slice, reflect.Array:
+		elems = make([]reflect.Value, reflectValue.Len())
+		for i := range elems {
+			elems[i] = reflectValue.Index(i)
+		}
+	}
+
+	for _, elem := range elems {
+		fieldValues := make([]interface{}, len(fields))
+		notZero := false
+		for idx, field := range fields {
+			var ok bool
+			fieldValues[idx], ok = fieldValueOf(ctx, field, elem)
+			notZero = notZero || ok
+		}
+
+		if notZero {
+			dataKey := utils.ToStringKey(fieldValues...)
+			if _, ok := dataResults[dataKey]; !ok {
+				results = append(results, fieldValues)
+			}
+			dataResults[dataKey] = append(dataResults[dataKey], elem)
+		}
+	}
+	return dataResults, results
+}
+
+// fieldValueOf reads field from a struct, or from a map keyed by either the field or the column name,
+// reporting whether it holds a non-zero value
+func fieldValueOf(ctx context.Context, field *gormSchema.Field, reflectValue reflect.Value) (interface{}, bool) {
+	if reflectValue = reflect.Indirect(reflectValue); reflectValue.Kind() == reflect.Map {
+		for _, key := range []string{field.Name, field.DBName} {
+			if value := reflectValue.MapIndex(reflect.ValueOf(key)); value.IsValid() {
+				return value.Interface(), !value.IsZero()
+			}
+		}
+		return nil, false
+	}
+
+	value, isZero := field.ValueOf(ctx, reflectValue)
+	return value, !isZero
+}
+
+// setFieldValue sets field on a struct, or on a map under the field name, where the value is
+// converted to the type of the field the same way field.Set does it for a struct
+func setFieldValue(ctx context.Context, field *gormSchema.Field, reflectValue reflect.Value, value interface{}) error {
+	if reflectValue = reflect.Indirect(reflectValue); reflectValue.Kind() != reflect.Map {
+		return field.Set(ctx, reflectValue, value)
+	}
+
+	model := reflect.New(field.Schema.ModelType).Elem()
+	if err := field.Set(ctx, model, value); err != nil {
+		return err
+	}
+
+	fieldValue := reflect.Zero(reflectValue.Type().Elem())
+	if v, _ := field.ValueOf(ctx, model); v != nil {
+		fieldValue = reflect.ValueOf(v)
+	}
+	reflectValue.SetMapIndex(reflect.ValueOf(field.Name), fieldValue)
+	return nil
+}
+
+// atomically runs fc so that an error rolls back everything it has written, under a
+// savepoint when the statement already runs in a transaction or an implicit one otherwise
+func atomically(db *gorm.DB, fc func() error) (err error) {
+	stmt := db.Statement
+
+	if _, ok := stmt.ConnPool.(gorm.TxCommitter); ok {
+		savePoint := fmt.Sprintf("sp%p", fc)
+		tx := db.Session(&gorm.Session{NewDB: true})
+		if err = db.Dialector.(gorm.SavePointerDialectorInterface).SavePoint(tx, savePoint); err != nil {
+			return
+		}
+		if err = fc(); err != nil {
+			db.AddError(db.Dialector.(gorm.SavePointerDialectorInterface).RollbackTo(tx, savePoint))
+		}
+		return
+	}
+
+	var (
+		connPool = stmt.ConnPool
+		txPool   gorm.ConnPool
+	)
+	switch beginner := connPool.(type) {
+	case gorm.TxBeginner:
+		txPool, err = beginner.BeginTx(stmt.Context, nil)
+	case gorm.ConnPoolBeginner:
+		txPool, err = beginner.BeginTx(stmt.Context, nil)
+	default:
+		return fc()
+	}
+	if err != nil {
+		return
+	}
+
+	stmt.ConnPool = txPool
+	defer func() {
+		stmt.ConnPool = connPool
+	}()
+
+	if err = fc(); err != nil {
+		db.AddError(txPool.(gorm.TxCommitter).Rollback())
+		return
+	}
+	return txPool.(gorm.TxCommitter).Commit()
 }
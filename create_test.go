@@ -0,0 +1,534 @@
+package oracle
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/godror/godror"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// recorder is a connection pool that records the statements executed on it instead of sending them,
+// the executions numbered in fails, counting from one, fail with their errors, the out binds of every
+// execution are set to the values of outs in turn, and its queries are answered with the rows of
+// results in turn and with no rows once they run out
+type recorder struct {
+	execs   []execution
+	fails   map[int]error
+	outs    [][]interface{}
+	queries []execution
+	results []result
+}
+
+type execution struct {
+	sql  string
+	vars []interface{}
+}
+
+func (r *recorder) PrepareContext(context.Context, string) (*sql.Stmt, error) {
+	return nil, errors.New("recorder prepares no statements")
+}
+
+func (r *recorder) ExecContext(_ context.Context, query string, args ...interface{}) (sql.Result, error) {
+	r.execs = append(r.execs, execution{sql: query, vars: args})
+	if err := r.fails[len(r.execs)]; err != nil {
+		return nil, err
+	}
+	if len(r.outs) > 0 {
+		outs := r.outs[0]
+		r.outs = r.outs[1:]
+		for _, arg := range args {
+			if out, ok := arg.(sql.Out); ok && len(outs) > 0 {
+				reflect.ValueOf(out.Dest).Elem().Set(reflect.ValueOf(outs[0]))
+				outs = outs[1:]
+			}
+		}
+	}
+	return driver.RowsAffected(1), nil
+}
+
+func (r *recorder) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	r.queries = append(r.queries, execution{sql: query, vars: args})
+	var res result
+	if len(r.results) > 0 {
+		res, r.results = r.results[0], r.results[1:]
+	}
+	return sql.OpenDB(res).QueryContext(ctx, query)
+}
+
+func (r *recorder) QueryRowContext(context.Context, string, ...interface{}) *sql.Row {
+	return nil
+}
+
+// txRecorder is a recorder within a transaction, recording its end as an execution
+type txRecorder struct {
+	*recorder
+}
+
+func (tx txRecorder) Commit() error {
+	tx.execs = append(tx.execs, execution{sql: "COMMIT"})
+	return tx.fails[len(tx.execs)]
+}
+
+func (tx txRecorder) Rollback() error {
+	tx.execs = append(tx.execs, execution{sql: "ROLLBACK"})
+	return tx.fails[len(tx.execs)]
+}
+
+// beginner is a recorder beginning transactions, recording their begin as an execution
+type beginner struct {
+	*recorder
+}
+
+func (b beginner) BeginTx(context.Context, *sql.TxOptions) (gorm.ConnPool, error) {
+	b.execs = append(b.execs, execution{sql: "BEGIN"})
+	if err := b.fails[len(b.execs)]; err != nil {
+		return nil, err
+	}
+	return &txRecorder{b.recorder}, nil
+}
+
+// result is a driver connection answering every query with its rows, *sql.Rows can not be made otherwise
+type result struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+func (res result) Connect(context.Context) (driver.Conn, error) { return res, nil }
+func (res result) Driver() driver.Driver                        { return nil }
+func (res result) Prepare(string) (driver.Stmt, error)          { return nil, driver.ErrSkip }
+func (res result) Close() error                                 { return nil }
+func (res result) Begin() (driver.Tx, error)                    { return nil, driver.ErrSkip }
+
+func (res result) QueryContext(context.Context, string, []driver.NamedValue) (driver.Rows, error) {
+	return &resultRows{result: res}, nil
+}
+
+type resultRows struct {
+	result
+	next int
+}
+
+func (rows *resultRows) Columns() []string { return rows.columns }
+
+func (rows *resultRows) Next(dest []driver.Value) error {
+	if rows.next >= len(rows.rows) {
+		return io.EOF
+	}
+	copy(dest, rows.rows[rows.next])
+	rows.next++
+	return nil
+}
+
+// recording opens a dialector executing its statements on a recorder
+func recording(t *testing.T, config Config) (*gorm.DB, *recorder) {
+	t.Helper()
+	if config.ServerVersion == "" {
+		config.ServerVersion = "19.0.0.0.0"
+	}
+	db, err := gorm.Open(New(config), &gorm.Config{DisableAutomaticPing: true, SkipDefaultTransaction: true})
+	if err != nil {
+		t.Fatalf("failed to open dialector, got error %v", err)
+	}
+	r := &recorder{}
+	db.ConnPool = r
+	db.Statement.ConnPool = r
+	return db, r
+}
+
+type arrayRow struct {
+	Code   string `gorm:"primaryKey"`
+	Name   string
+	Score  int
+	Active bool
+}
+
+type identityRow struct {
+	ID   uint `gorm:"autoIncrement"`
+	Name string
+}
+
+type sequenceRow struct {
+	ID   uint `gorm:"primaryKey;sequence:sequence_rows_seq"`
+	Name string
+}
+
+func TestCreateArrayBinds(t *testing.T) {
+	db, r := recording(t, Config{})
+	rows := []arrayRow{{Code: "a", Name: "A", Score: 1, Active: true}, {Code: "b", Score: 2}, {Code: "c", Name: "C", Score: 3}}
+	if err := db.Create(&rows).Error; err != nil {
+		t.Fatalf("failed to create, got error %v", err)
+	}
+
+	if len(r.execs) != 1 {
+		t.Fatalf("rows should go in a single execution, got %d", len(r.execs))
+	}
+	if want := "INSERT INTO ARRAY_ROWS (CODE,NAME,SCORE,ACTIVE) VALUES (:1,:2,:3,:4)"; r.execs[0].sql != want {
+		t.Errorf("got SQL\n%s\nwant\n%s", r.execs[0].sql, want)
+	}
+	want := []interface{}{[]string{"a", "b", "c"}, []string{"A", "", "C"}, []int64{1, 2, 3}, []int64{1, 0, 0}}
+	if !reflect.DeepEqual(r.execs[0].vars, want) {
+		t.Errorf("got vars %#v, want %#v", r.execs[0].vars, want)
+	}
+}
+
+func TestCreateForall(t *testing.T) {
+	db, r := recording(t, Config{})
+	if err := db.Create(&[]identityRow{{Name: "a"}, {Name: "b"}}).Error; err != nil {
+		t.Fatalf("failed to create, got error %v", err)
+	}
+
+	if len(r.execs) != 1 {
+		t.Fatalf("rows should go in a single FORALL block, got %d executions", len(r.execs))
+	}
+	exec := r.execs[0]
+	if want := "BEGIN FORALL i IN 1 .. 2 INSERT INTO IDENTITY_ROWS (NAME) VALUES (:1(i)) RETURNING ID BULK COLLECT INTO :2; END;"; exec.sql != want {
+		t.Errorf("got SQL\n%s\nwant\n%s", exec.sql, want)
+	}
+	// the names, the out bind of the ids, then the options binding PL/SQL arrays of two elements
+	if len(exec.vars) != 4 || !reflect.DeepEqual(exec.vars[0], []string{"a", "b"}) {
+		t.Fatalf("got vars %#v", exec.vars)
+	}
+	if _, ok := exec.vars[1].(sql.Out); !ok {
+		t.Errorf("ids should be collected into an out bind, got %#v", exec.vars[1])
+	}
+	for _, option := range exec.vars[2:] {
+		if _, ok := option.(godror.Option); !ok {
+			t.Errorf("got %#v, want a godror.Option", option)
+		}
+	}
+}
+
+func TestCreateForallInlineExpression(t *testing.T) {
+	// the colons of an expression shared by all rows are no placeholders
+	db, r := recording(t, Config{})
+	stamp := gorm.Expr("TIMESTAMP '2024-01-01 10:00:00'")
+	if err := db.Model(&identityRow{}).Create([]map[string]interface{}{{"Name": stamp}, {"Name": stamp}}).Error; err != nil {
+		t.Fatalf("failed to create, got error %v", err)
+	}
+
+	if len(r.execs) != 1 {
+		t.Fatalf("rows should go in a single FORALL block, got %d executions", len(r.execs))
+	}
+	if want := "BEGIN FORALL i IN 1 .. 2 INSERT INTO IDENTITY_ROWS (NAME) VALUES (TIMESTAMP '2024-01-01 10:00:00') RETURNING ID BULK COLLECT INTO :1; END;"; r.execs[0].sql != want {
+		t.Errorf("got SQL\n%s\nwant\n%s", r.execs[0].sql, want)
+	}
+}
+
+func TestCreateMapReturning(t *testing.T) {
+	// the returned keys are written to the maps under the name of their field
+	db, r := recording(t, Config{})
+	r.outs = [][]interface{}{{uint(7)}}
+	row := map[string]interface{}{"Name": "a"}
+	if err := db.Model(&identityRow{}).Create(row).Error; err != nil {
+		t.Fatalf("failed to create, got error %v", err)
+	}
+	if row["ID"] != uint(7) {
+		t.Errorf("got %#v, want the returned ID", row)
+	}
+
+	// a FORALL block bulk collects them into an array for all the rows
+	db, r = recording(t, Config{})
+	r.outs = [][]interface{}{{[]sql.NullInt64{{Int64: 8, Valid: true}, {Int64: 9, Valid: true}}}}
+	rows := []map[string]interface{}{{"Name": "b"}, {"Name": "c"}}
+	if err := db.Model(&identityRow{}).Create(rows).Error; err != nil {
+		t.Fatalf("failed to create, got error %v", err)
+	}
+	if len(r.execs) != 1 || !strings.HasPrefix(r.execs[0].sql, "BEGIN FORALL") {
+		t.Fatalf("rows should go in a single FORALL block, got %d executions", len(r.execs))
+	}
+	if rows[0]["ID"] != uint(8) || rows[1]["ID"] != uint(9) {
+		t.Errorf("got %#v, want the returned IDs", rows)
+	}
+}
+
+func TestCreateMixedDefaults(t *testing.T) {
+	// rows leaving a default valued field to the database in a batch setting it get DEFAULT, or
+	// the next value of their sequence, and go in one by one
+	for _, tt := range []struct {
+		name  string
+		value interface{}
+		sql   []string
+	}{
+		{
+			name:  "identity",
+			value: &[]identityRow{{ID: 5, Name: "a"}, {Name: "b"}},
+			sql: []string{
+				"INSERT INTO IDENTITY_ROWS (NAME,ID) VALUES (:1,:2) RETURNING ID INTO :3",
+				"INSERT INTO IDENTITY_ROWS (NAME,ID) VALUES (:1,DEFAULT) RETURNING ID INTO :2",
+			},
+		},
+		{
+			name:  "sequence",
+			value: &[]sequenceRow{{ID: 5, Name: "a"}, {Name: "b"}},
+			sql: []string{
+				"INSERT INTO SEQUENCE_ROWS (NAME,ID) VALUES (:1,:2) RETURNING ID INTO :3",
+				"INSERT INTO SEQUENCE_ROWS (NAME,ID) VALUES (:1,sequence_rows_seq.NEXTVAL) RETURNING ID INTO :2",
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			db, r := recording(t, Config{})
+			if err := db.Create(tt.value).Error; err != nil {
+				t.Fatalf("failed to create, got error %v", err)
+			}
+			if len(r.execs) != len(tt.sql) {
+				t.Fatalf("got %d executions, want %d", len(r.execs), len(tt.sql))
+			}
+			for idx, exec := range r.execs {
+				if exec.sql != tt.sql[idx] {
+					t.Errorf("got SQL\n%s\nwant\n%s", exec.sql, tt.sql[idx])
+				}
+			}
+		})
+	}
+}
+
+func TestCreateUpsertTarget(t *testing.T) {
+	// a MERGE can not match on a column its source leaves out
+	tx := dryRun(t, Config{}).Model(&arrayRow{}).Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "Name"}}, UpdateAll: true}).
+		Create(&[]map[string]interface{}{{"Code": "a"}})
+	if !errors.Is(tx.Error, gorm.ErrInvalidField) {
+		t.Errorf("a target that is not inserted should fail with gorm.ErrInvalidField, got error %v", tx.Error)
+	}
+
+	// while a primary key left to the database conflicts with nothing
+	tx = dryRun(t, Config{}).Clauses(clause.OnConflict{UpdateAll: true}).Create(&identityRow{Name: "a"})
+	if want := "INSERT INTO IDENTITY_ROWS (NAME) VALUES (:1) RETURNING ID INTO :2"; tx.Error != nil || tx.Statement.SQL.String() != want {
+		t.Errorf("got SQL\n%s\nwant\n%s\nand error %v", tx.Statement.SQL.String(), want, tx.Error)
+	}
+}
+
+func TestCreateUpsertDefaults(t *testing.T) {
+	// rows leaving the identity to the database are merged apart, without inserting the NULL in its place
+	db, r := recording(t, Config{})
+	r.results = []result{{columns: []string{"ID", "NAME"}, rows: [][]driver.Value{{int64(5), "a"}, {int64(6), "b"}}}}
+	rows := []identityRow{{ID: 5, Name: "a"}, {Name: "b"}}
+	tx := db.Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "NAME"}}, DoUpdates: clause.AssignmentColumns([]string{"ID"})}).Create(&rows)
+	if tx.Error != nil {
+		t.Fatalf("failed to upsert, got error %v", tx.Error)
+	}
+
+	want := []string{
+		"MERGE INTO IDENTITY_ROWS USING (SELECT :1 AS NAME,:2 AS ID FROM DUAL) excluded ON (IDENTITY_ROWS.NAME = excluded.NAME) " +
+			"WHEN MATCHED THEN UPDATE SET ID=excluded.ID WHEN NOT MATCHED THEN INSERT (NAME,ID) VALUES (excluded.NAME,excluded.ID)",
+		"MERGE INTO IDENTITY_ROWS USING (SELECT :1 AS NAME,:2 AS ID FROM DUAL) excluded ON (IDENTITY_ROWS.NAME = excluded.NAME) " +
+			"WHEN NOT MATCHED THEN INSERT (NAME) VALUES (excluded.NAME)",
+	}
+	if len(r.execs) != len(want) {
+		t.Fatalf("got %d executions, want %d", len(r.execs), len(want))
+	}
+	for idx, exec := range r.execs {
+		if exec.sql != want[idx] {
+			t.Errorf("got SQL\n%s\nwant\n%s", exec.sql, want[idx])
+		}
+	}
+	if !reflect.DeepEqual(r.execs[1].vars, []interface{}{"b", nil}) {
+		t.Errorf("got vars %#v, want the name and NULL", r.execs[1].vars)
+	}
+	if tx.RowsAffected != 2 {
+		t.Errorf("got %d rows affected, want the sum of both merges", tx.RowsAffected)
+	}
+	if rows[1].ID != 6 {
+		t.Errorf("the identity should be read back, got %d", rows[1].ID)
+	}
+}
+
+func TestCreateAtomically(t *testing.T) {
+	unique := newOraErr(1, 0, "unique constraint (SCOTT.SYS_C008123) violated")
+	for _, tt := range []struct {
+		name  string
+		pool  func(*recorder) gorm.ConnPool
+		fails map[int]error
+		value interface{}
+		sql   []string
+		index int
+	}{
+		{
+			name:  "implicit transaction",
+			pool:  func(r *recorder) gorm.ConnPool { return &beginner{r} },
+			value: &[]arrayRow{{Code: "a"}, {Code: "b"}},
+			sql:   []string{"BEGIN", "INSERT INTO ARRAY_ROWS (CODE,NAME,SCORE,ACTIVE) VALUES (:1,:2,:3,:4)", "COMMIT"},
+			index: -1,
+		},
+		{
+			// for array DML oracle tells the failing row by the offset of its error
+			name:  "array DML",
+			pool:  func(r *recorder) gorm.ConnPool { return &beginner{r} },
+			fails: map[int]error{2: newOraErr(1, 1, "unique constraint (SCOTT.SYS_C008123) violated")},
+			value: &[]arrayRow{{Code: "a"}, {Code: "b"}},
+			sql:   []string{"BEGIN", "INSERT INTO ARRAY_ROWS (CODE,NAME,SCORE,ACTIVE) VALUES (:1,:2,:3,:4)", "ROLLBACK"},
+			index: 1,
+		},
+		{
+			// a FORALL block does not, so the rows go in one by one to find it
+			name:  "forall",
+			pool:  func(r *recorder) gorm.ConnPool { return &beginner{r} },
+			fails: map[int]error{2: unique, 4: unique},
+			value: &[]identityRow{{Name: "a"}, {Name: "b"}, {Name: "c"}},
+			sql: []string{
+				"BEGIN",
+				"BEGIN FORALL i IN 1 .. 3 INSERT INTO IDENTITY_ROWS (NAME) VALUES (:1(i)) RETURNING ID BULK COLLECT INTO :2; END;",
+				"INSERT INTO IDENTITY_ROWS (NAME) VALUES (:1) RETURNING ID INTO :2",
+				"INSERT INTO IDENTITY_ROWS (NAME) VALUES (:1) RETURNING ID INTO :2",
+				"ROLLBACK",
+			},
+			index: 1,
+		},
+		{
+			name:  "savepoint",
+			pool:  func(r *recorder) gorm.ConnPool { return &txRecorder{r} },
+			fails: map[int]error{3: unique},
+			value: &[]identityRow{{ID: 5, Name: "a"}, {Name: "b"}},
+			sql: []string{
+				"SAVEPOINT",
+				"INSERT INTO IDENTITY_ROWS (NAME,ID) VALUES (:1,:2) RETURNING ID INTO :3",
+				"INSERT INTO IDENTITY_ROWS (NAME,ID) VALUES (:1,DEFAULT) RETURNING ID INTO :2",
+				"ROLLBACK TO SAVEPOINT",
+			},
+			index: 1,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			db, r := recording(t, Config{})
+			r.fails = tt.fails
+			db.Statement.ConnPool = tt.pool(r)
+
+			err := db.Create(tt.value).Error
+			var rowErr *RowError
+			if tt.index < 0 && err != nil {
+				t.Fatalf("failed to create, got error %v", err)
+			} else if tt.index >= 0 && (!errors.As(err, &rowErr) || rowErr.Index != tt.index) {
+				t.Fatalf("creating should fail at row %d, got error %v", tt.index, err)
+			}
+
+			if len(r.execs) != len(tt.sql) {
+				t.Fatalf("got %d executions, want %d", len(r.execs), len(tt.sql))
+			}
+			for idx, exec := range r.execs {
+				// savepoints are named after the function they protect
+				if !strings.HasPrefix(exec.sql, tt.sql[idx]) {
+					t.Errorf("got SQL\n%s\nwant\n%s", exec.sql, tt.sql[idx])
+				}
+			}
+		})
+	}
+}
+
+func TestCreateUpsertReadBack(t *testing.T) {
+	// the identities of merged rows are read back by their conflict key, whatever order they come in,
+	// a thousand keys at most in an IN list
+	db, r := recording(t, Config{})
+	rows := make([]identityRow, 1001)
+	first := result{columns: []string{"ID", "NAME"}}
+	for idx := range rows {
+		rows[idx].Name = strconv.Itoa(idx)
+		if idx < 1000 {
+			first.rows = append(first.rows, []driver.Value{int64(idx + 1), rows[idx].Name})
+		}
+	}
+	first.rows[0], first.rows[1] = first.rows[1], first.rows[0]
+	r.results = []result{first, {columns: []string{"ID", "NAME"}, rows: [][]driver.Value{{int64(1001), "1000"}}}}
+	if err := db.Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "NAME"}}, DoNothing: true}).Create(&rows).Error; err != nil {
+		t.Fatalf("failed to upsert, got error %v", err)
+	}
+
+	if len(r.queries) != 2 || len(r.queries[0].vars) != 1000 || len(r.queries[1].vars) != 1 {
+		t.Fatalf("the keys should be read back in chunks of a thousand, got %d queries", len(r.queries))
+	}
+	for idx, row := range rows {
+		if row.ID != uint(idx+1) {
+			t.Fatalf("row %d got ID %d, want %d", idx, row.ID, idx+1)
+		}
+	}
+
+	// and written to maps under the name of their field
+	db, r = recording(t, Config{})
+	r.results = []result{{columns: []string{"ID", "NAME"}, rows: [][]driver.Value{{int64(2), "b"}, {int64(1), "a"}}}}
+	values := []map[string]interface{}{{"Name": "a"}, {"Name": "b"}}
+	if err := db.Model(&identityRow{}).Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "NAME"}}, DoNothing: true}).Create(values).Error; err != nil {
+		t.Fatalf("failed to upsert, got error %v", err)
+	}
+	if values[0]["ID"] != uint(1) || values[1]["ID"] != uint(2) {
+		t.Errorf("got %#v, want the IDs read back", values)
+	}
+}
+
+func TestCreateEmptySlice(t *testing.T) {
+	db, r := recording(t, Config{})
+	if err := db.Create(&[]identityRow{}).Error; !errors.Is(err, gorm.ErrEmptySlice) {
+		t.Errorf("creating an empty slice should fail with gorm.ErrEmptySlice, got error %v", err)
+	}
+	if len(r.execs) != 0 {
+		t.Errorf("an empty slice should execute nothing, got %d executions", len(r.execs))
+	}
+}
+
+func TestCreateUpsert(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		conflict clause.OnConflict
+		value    interface{}
+		sql      string
+	}{
+		{
+			name:     "update all",
+			conflict: clause.OnConflict{UpdateAll: true},
+			value:    &[]arrayRow{{Code: "a", Name: "A"}, {Code: "b", Name: "B"}},
+			sql: "MERGE INTO ARRAY_ROWS USING (SELECT :1 AS CODE,:2 AS NAME,:3 AS SCORE,:4 AS ACTIVE FROM DUAL UNION ALL SELECT :5,:6,:7,:8 FROM DUAL) excluded " +
+				"ON (ARRAY_ROWS.CODE = excluded.CODE) WHEN MATCHED THEN UPDATE SET NAME=excluded.NAME,SCORE=excluded.SCORE,ACTIVE=excluded.ACTIVE " +
+				"WHEN NOT MATCHED THEN INSERT (CODE,NAME,SCORE,ACTIVE) VALUES (excluded.CODE,excluded.NAME,excluded.SCORE,excluded.ACTIVE)",
+		},
+		{
+			name:     "do nothing",
+			conflict: clause.OnConflict{Columns: []clause.Column{{Name: "NAME"}}, DoNothing: true},
+			value:    &arrayRow{Code: "a", Name: "A"},
+			sql: "MERGE INTO ARRAY_ROWS USING (SELECT :1 AS CODE,:2 AS NAME,:3 AS SCORE,:4 AS ACTIVE FROM DUAL) excluded " +
+				"ON (ARRAY_ROWS.NAME = excluded.NAME) " +
+				"WHEN NOT MATCHED THEN INSERT (CODE,NAME,SCORE,ACTIVE) VALUES (excluded.CODE,excluded.NAME,excluded.SCORE,excluded.ACTIVE)",
+		},
+		{
+			// the target is looked up by its field or column name in any case
+			name:     "field named target",
+			conflict: clause.OnConflict{Columns: []clause.Column{{Name: "name"}}, DoUpdates: clause.AssignmentColumns([]string{"SCORE"})},
+			value:    &arrayRow{Code: "a", Name: "A"},
+			sql: "MERGE INTO ARRAY_ROWS USING (SELECT :1 AS CODE,:2 AS NAME,:3 AS SCORE,:4 AS ACTIVE FROM DUAL) excluded " +
+				"ON (ARRAY_ROWS.NAME = excluded.NAME) WHEN MATCHED THEN UPDATE SET SCORE=excluded.SCORE " +
+				"WHEN NOT MATCHED THEN INSERT (CODE,NAME,SCORE,ACTIVE) VALUES (excluded.CODE,excluded.NAME,excluded.SCORE,excluded.ACTIVE)",
+		},
+		{
+			// NEXTVAL can not go in the source, rows without a key leave it NULL there
+			name:     "sequence",
+			conflict: clause.OnConflict{Columns: []clause.Column{{Name: "NAME"}}, DoNothing: true},
+			value:    &[]sequenceRow{{ID: 5, Name: "a"}, {Name: "b"}},
+			sql: "MERGE INTO SEQUENCE_ROWS USING (SELECT :1 AS NAME,:2 AS ID FROM DUAL UNION ALL SELECT :3,:4 FROM DUAL) excluded " +
+				"ON (SEQUENCE_ROWS.NAME = excluded.NAME) " +
+				"WHEN NOT MATCHED THEN INSERT (NAME,ID) VALUES (excluded.NAME,NVL(excluded.ID, sequence_rows_seq.NEXTVAL))",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			tx := dryRun(t, Config{}).Clauses(tt.conflict).Create(tt.value)
+			if tx.Error != nil {
+				t.Fatalf("failed to build upsert, got error %v", tx.Error)
+			}
+			if sql := tx.Statement.SQL.String(); sql != tt.sql {
+				t.Errorf("got SQL\n%s\nwant\n%s", sql, tt.sql)
+			}
+		})
+	}
+
+	// DEFAULT has no place in the source, the row leaving the key to the sequence binds NULL
+	tx := dryRun(t, Config{}).Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "NAME"}}, DoNothing: true}).
+		Create(&[]sequenceRow{{ID: 5, Name: "a"}, {Name: "b"}})
+	if want := []interface{}{"a", uint(5), "b", nil}; !reflect.DeepEqual(tx.Statement.Vars, want) {
+		t.Errorf("got vars %#v, want %#v", tx.Statement.Vars, want)
+	}
+}
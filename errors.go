@@ -0,0 +1,169 @@
+package oracle
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/godror/godror"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+var (
+	// ErrCheckConstraintViolated is what Translate turns ORA-02290 into
+	ErrCheckConstraintViolated = errors.New("violates check constraint")
+	// ErrNotNullViolated is what Translate turns ORA-01400 and ORA-01407 into
+	ErrNotNullViolated = errors.New("violates not null constraint")
+)
+
+// errorTranslations maps ORA- codes to the errors Translate reports them as
+var errorTranslations = map[int]error{
+	1:    gorm.ErrDuplicatedKey,
+	1400: ErrNotNullViolated,
+	1407: ErrNotNullViolated,
+	2290: ErrCheckConstraintViolated,
+	2291: gorm.ErrForeignKeyViolated,
+	2292: gorm.ErrForeignKeyViolated,
+}
+
+// Translate turns an ORA- error into an *Error, with gorm.Config.TranslateError set, which errors.Is
+// matches against the gorm error its code stands for, errors.As still finds the *godror.OraErr
+func (d Dialector) Translate(err error) error {
+	var oraErr *godror.OraErr
+	if errors.As(err, &oraErr) {
+		if translated, ok := errorTranslations[oraErr.Code()]; ok {
+			e := newError(err, oraErr.Code(), oraErr.Message())
+			e.translated = translated
+			return e
+		}
+	}
+	return err
+}
+
+var (
+	// the constraint of ORA-00001, ORA-02290, ORA-02291 and ORA-02292, e.g. (SCOTT.IDX_USERS_EMAIL) violated
+	constraintPattern = regexp.MustCompile(`\(([^()]+)\) violated`)
+	// the table and columns 21c adds to ORA-00001, e.g. on table SCOTT.USERS columns (EMAIL)
+	constraintTablePattern = regexp.MustCompile(`on table ([^ ]+) columns \(([^)]+)\)`)
+	// the column of ORA-01400, ORA-01407 and ORA-12899, e.g. ("SCOTT"."USERS"."EMAIL")
+	columnPattern = regexp.MustCompile(`"([^"]+)"\."([^"]+)"\."([^"]+)"`)
+)
+
+// Error is an ORA- error with the constraint, table and column it is about, as far as its message
+// tells them, and the Field of the statement's schema they resolve to
+type Error struct {
+	Code       int
+	Message    string
+	Constraint string
+	Table      string
+	Column     string
+	Field      *schema.Field
+	Err        error
+	translated error
+}
+
+// newError parses the message of the ORA- error with code, err is the error it was found in
+func newError(err error, code int, message string) *Error {
+	e := &Error{Code: code, Message: message, Err: err}
+	if match := constraintPattern.FindStringSubmatch(e.Message); match != nil {
+		e.Constraint = unqualify(match[1])
+	}
+	if match := constraintTablePattern.FindStringSubmatch(e.Message); match != nil {
+		e.Table = unqualify(match[1])
+		e.Column = strings.Trim(strings.TrimSpace(strings.Split(match[2], ",")[0]), `"`)
+	}
+	if match := columnPattern.FindStringSubmatch(e.Message); match != nil {
+		e.Table, e.Column = match[2], match[3]
+	}
+	return e
+}
+
+// unqualify strips the owner off a name, e.g. SCOTT.USERS
+func unqualify(name string) string {
+	if idx := strings.LastIndexByte(name, '.'); idx >= 0 {
+		name = name[idx+1:]
+	}
+	return strings.Trim(name, `"`)
+}
+
+// resolve finds the field the error is about in s, by the column it names or by the index, check or
+// foreign key constraint of s it names
+func (e *Error) resolve(s *schema.Schema) {
+	if e.Field != nil || s == nil {
+		return
+	}
+
+	if e.Column != "" && (e.Table == "" || strings.EqualFold(e.Table, s.Table)) {
+		e.Field = s.LookUpField(e.Column)
+	}
+	if e.Field == nil && e.Constraint != "" {
+		for _, idx := range s.ParseIndexes() {
+			if strings.EqualFold(idx.Name, e.Constraint) && len(idx.Fields) > 0 {
+				e.Field = idx.Fields[0].Field
+			}
+		}
+		for _, chk := range s.ParseCheckConstraints() {
+			if strings.EqualFold(chk.Name, e.Constraint) {
+				e.Field = chk.Field
+			}
+		}
+		for _, rel := range s.Relationships.Relations {
+			if constraint := rel.ParseConstraint(); constraint != nil && strings.EqualFold(constraint.Name, e.Constraint) {
+				// a missing parent fails on the foreign key, a remaining child on the key it references
+				if constraint.Schema == s && len(constraint.ForeignKeys) > 0 {
+					e.Field = constraint.ForeignKeys[0]
+				} else if len(constraint.References) > 0 {
+					e.Field = constraint.References[0]
+				}
+			}
+		}
+	}
+
+	if e.Field != nil {
+		e.Table, e.Column = e.Field.Schema.Table, e.Field.DBName
+	}
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e *Error) Is(target error) bool {
+	return e.translated != nil && target == e.translated
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// ResolveError turns an ORA- error of the statement into an *Error and resolves the field it is
+// about against the statement's schema
+func ResolveError(db *gorm.DB) {
+	var oraErr *godror.OraErr
+	if db.Error == nil || !errors.As(db.Error, &oraErr) {
+		return
+	}
+
+	var e *Error
+	if !errors.As(db.Error, &e) {
+		e = newError(db.Error, oraErr.Code(), oraErr.Message())
+		db.Error = e
+	}
+	e.resolve(db.Statement.Schema)
+}
+
+// RowError reports which element of a multi-row Create made the statement fail
+type RowError struct {
+	Index int
+	Err   error
+}
+
+func (e *RowError) Error() string {
+	return fmt.Sprintf("failed to create slice data #%d: %v", e.Index, e.Err)
+}
+
+func (e *RowError) Unwrap() error {
+	return e.Err
+}
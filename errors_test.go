@@ -0,0 +1,145 @@
+package oracle
+
+import (
+	"errors"
+	"testing"
+	"unsafe"
+
+	"github.com/godror/godror"
+	"gorm.io/gorm"
+)
+
+// oraErr has the layout of godror.OraErr, which is only made by the driver, for tests to make their own
+type oraErr struct {
+	message, funName, action, sqlState string
+	code, offset                       int
+	recoverable, warning               bool
+}
+
+// newOraErr makes the godror.OraErr of the ORA- error code, at the row offset of bulk operations
+func newOraErr(code, offset int, message string) *godror.OraErr {
+	return (*godror.OraErr)(unsafe.Pointer(&oraErr{message: message, code: code, offset: offset}))
+}
+
+type erredUser struct {
+	ID        uint
+	Email     string `gorm:"uniqueIndex"`
+	Name      string `gorm:"not null"`
+	CompanyID uint
+	Company   erredCompany
+}
+
+type erredCompany struct {
+	ID   uint
+	Name string
+}
+
+func TestNewError(t *testing.T) {
+	for _, tt := range []struct {
+		code       int
+		message    string
+		constraint string
+		table      string
+		column     string
+	}{
+		{
+			code:       1,
+			message:    "ORA-00001: unique constraint (SCOTT.IDX_ERRED_USERS_EMAIL) violated",
+			constraint: "IDX_ERRED_USERS_EMAIL",
+		},
+		{
+			code:       1,
+			message:    "ORA-00001: unique constraint (SCOTT.SYS_C008123) violated on table SCOTT.ERRED_USERS columns (EMAIL, NAME)",
+			constraint: "SYS_C008123",
+			table:      "ERRED_USERS",
+			column:     "EMAIL",
+		},
+		{
+			code:    1400,
+			message: `ORA-01400: cannot insert NULL into ("SCOTT"."ERRED_USERS"."NAME")`,
+			table:   "ERRED_USERS",
+			column:  "NAME",
+		},
+		{
+			code:    12899,
+			message: `ORA-12899: value too large for column "SCOTT"."ERRED_USERS"."EMAIL" (actual: 300, maximum: 255)`,
+			table:   "ERRED_USERS",
+			column:  "EMAIL",
+		},
+		{
+			code:       2291,
+			message:    "ORA-02291: integrity constraint (SCOTT.FK_ERRED_USERS_COMPANY) violated - parent key not found",
+			constraint: "FK_ERRED_USERS_COMPANY",
+		},
+		{
+			code:       2290,
+			message:    `ORA-02290: check constraint ("SCOTT"."CHK_ERRED_USERS_ACTIVE_BOOL") violated`,
+			constraint: "CHK_ERRED_USERS_ACTIVE_BOOL",
+		},
+	} {
+		e := newError(errors.New(tt.message), tt.code, tt.message)
+		if e.Code != tt.code || e.Constraint != tt.constraint || e.Table != tt.table || e.Column != tt.column {
+			t.Errorf("%s parsed as code %d, constraint %q, table %q and column %q", tt.message, e.Code, e.Constraint, e.Table, e.Column)
+		}
+		if e.Error() != tt.message {
+			t.Errorf("error should read as the one it was found in, got %s", e.Error())
+		}
+	}
+}
+
+func TestErrorResolve(t *testing.T) {
+	stmt := &gorm.Statement{DB: dryRun(t, Config{})}
+	if err := stmt.Parse(&erredUser{}); err != nil {
+		t.Fatalf("failed to parse model, got error %v", err)
+	}
+
+	for _, tt := range []struct {
+		code    int
+		message string
+		field   string
+	}{
+		{1, "ORA-00001: unique constraint (SCOTT.IDX_ERRED_USERS_EMAIL) violated", "Email"},
+		{1, "ORA-00001: unique constraint (SCOTT.SYS_C008123) violated on table SCOTT.ERRED_USERS columns (EMAIL)", "Email"},
+		{1400, `ORA-01400: cannot insert NULL into ("SCOTT"."ERRED_USERS"."NAME")`, "Name"},
+		{2291, "ORA-02291: integrity constraint (SCOTT.FK_ERRED_USERS_COMPANY) violated - parent key not found", "CompanyID"},
+		{1400, `ORA-01400: cannot insert NULL into ("SCOTT"."OTHER_TABLE"."NAME")`, ""},
+	} {
+		e := newError(errors.New(tt.message), tt.code, tt.message)
+		e.resolve(stmt.Schema)
+
+		var field string
+		if e.Field != nil {
+			field = e.Field.Name
+		}
+		if field != tt.field {
+			t.Errorf("%s resolved to field %q, want %q", tt.message, field, tt.field)
+		}
+	}
+}
+
+func TestTranslateOther(t *testing.T) {
+	err := errors.New("connection refused")
+	if translated := (Dialector{Config: &Config{}}).Translate(err); translated != err {
+		t.Errorf("errors other than ORA- ones should be left alone, got %v", translated)
+	}
+}
+
+func TestErrorIs(t *testing.T) {
+	message := "ORA-00001: unique constraint (SCOTT.IDX_ERRED_USERS_EMAIL) violated"
+	e := newError(errors.New(message), 1, message)
+	if errors.Is(e, gorm.ErrDuplicatedKey) {
+		t.Errorf("an untranslated error should not match gorm.ErrDuplicatedKey")
+	}
+	e.translated = errorTranslations[e.Code]
+	if !errors.Is(e, gorm.ErrDuplicatedKey) || errors.Is(e, gorm.ErrForeignKeyViolated) {
+		t.Errorf("ORA-00001 should match gorm.ErrDuplicatedKey only")
+	}
+}
+
+func TestNewOraErr(t *testing.T) {
+	// the layout of godror.OraErr is mirrored, make sure it still is
+	err := newOraErr(1, 2, "unique constraint (SCOTT.PK) violated")
+	if err.Code() != 1 || err.Offset() != 2 || err.Error() != "ORA-00001: unique constraint (SCOTT.PK) violated" {
+		t.Errorf("got code %d, offset %d and error %s", err.Code(), err.Offset(), err.Error())
+	}
+}
@@ -0,0 +1,89 @@
+package oracle
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm/schema"
+	"gorm.io/gorm/utils"
+)
+
+// IdentityOptions are the options of identity columns, Generated is one of ALWAYS, BY DEFAULT
+// (the default) or BY DEFAULT ON NULL
+type IdentityOptions struct {
+	Generated string
+	SequenceOptions
+}
+
+// identityOptionsOf returns the identity options of field, the dialector's ones overridden by
+// its tags, e.g. gorm:"identity:always;identityStart:100;identityIncrement:10;identityCache:20"
+func (d Dialector) identityOptionsOf(field *schema.Field) IdentityOptions {
+	opts := d.Identity
+	if value, ok := field.TagSettings["IDENTITY"]; ok && value != "IDENTITY" {
+		opts.Generated = value
+	}
+	if opts.Generated = strings.ToUpper(strings.Join(strings.Fields(opts.Generated), " ")); opts.Generated == "" {
+		opts.Generated = "BY DEFAULT"
+	}
+
+	for tag, option := range map[string]*int64{
+		"IDENTITYSTART":     &opts.StartWith,
+		"IDENTITYINCREMENT": &opts.IncrementBy,
+		"IDENTITYCACHE":     &opts.Cache,
+	} {
+		if value, ok := field.TagSettings[tag]; ok {
+			if strings.EqualFold(value, "NOCACHE") {
+				opts.NoCache = true
+				continue
+			}
+			n, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				panic(fmt.Sprintf("invalid %s %q for field %s", strings.ToLower(tag), value, field.Name))
+			}
+			*option = n
+		}
+	}
+	return opts
+}
+
+// isIdentity reports whether the field is generated by the database, as an identity column or by
+// a sequence and trigger, rather than drawn from a tagged sequence. Like before, it takes an explicit
+// autoIncrement or identity tag, gorm marks every untagged integer primary key auto increment
+func isIdentity(field *schema.Field) bool {
+	if field.DBName == "" || sequenceOf(field) != "" {
+		return false
+	}
+	if _, ok := field.TagSettings["IDENTITY"]; ok {
+		return true
+	}
+	value, ok := field.TagSettings["AUTOINCREMENT"]
+	return ok && utils.CheckTruth(value)
+}
+
+// identityOf returns the identity clause following the data type of field
+func (d Dialector) identityOf(field *schema.Field) string {
+	opts := d.identityOptionsOf(field)
+	switch opts.Generated {
+	case "ALWAYS", "BY DEFAULT", "BY DEFAULT ON NULL":
+	default:
+		panic(fmt.Sprintf("invalid identity %s for field %s", opts.Generated, field.Name))
+	}
+
+	sql := " GENERATED " + opts.Generated + " AS IDENTITY"
+	if options := opts.sql(false); options != "" {
+		sql += " (" + strings.TrimSpace(options) + ")"
+	}
+	return sql
+}
+
+// identitySequenceName is the name of the sequence backing the identity of table when
+// identity columns are emulated by a sequence and trigger
+func identitySequenceName(table string) string {
+	return table + "_SEQ"
+}
+
+// identityTriggerName is the name of the BEFORE INSERT trigger filling in the identity of table
+func identityTriggerName(table string) string {
+	return table + "_TRG"
+}
@@ -0,0 +1,18 @@
+package oracle
+
+import (
+	"gorm.io/gorm"
+)
+
+// Merge builds the MERGE statement put together from clauses.Merge, clauses.WhenMatched and
+// clauses.WhenNotMatched when it is run as db.Clauses(...).Exec("")
+func Merge(db *gorm.DB) {
+	stmt := db.Statement
+	if db.Error != nil || stmt.SQL.Len() > 0 {
+		return
+	}
+
+	if _, ok := stmt.Clauses["MERGE"]; ok {
+		stmt.Build("MERGE", "WHEN MATCHED", "WHEN NOT MATCHED")
+	}
+}
@@ -1,12 +1,16 @@
 package oracle
 
 import (
+	"context"
 	"fmt"
+	"reflect"
+	"regexp"
 	"strings"
 
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 	"gorm.io/gorm/migrator"
+	"gorm.io/gorm/schema"
 )
 
 type Migrator struct {
@@ -15,17 +19,243 @@ type Migrator struct {
 
 func (m Migrator) CurrentDatabase() (name string) {
 	m.DB.Raw(
-		fmt.Sprintf(`SELECT ORA_DATABASE_NAME as "Current Database" FROM %s`, m.Dialector.(Dialector).DummyTableName()),
+		`SELECT ORA_DATABASE_NAME as "Current Database"` + m.Dialector.(Dialector).FromDual(),
 	).Row().Scan(&name)
 	return
 }
 
+func (m Migrator) AutoMigrate(values ...interface{}) error {
+	if err := m.createSequences(values...); err != nil {
+		return err
+	}
+	if err := m.Migrator.AutoMigrate(values...); err != nil {
+		return err
+	}
+	if err := m.createColumnChecks(values...); err != nil {
+		return err
+	}
+	// in trigger mode, auto increment columns of existing tables not yet generated by the database
+	// get a sequence and trigger as well
+	if m.Dialector.(Dialector).IdentityTrigger {
+		return m.createIdentityTriggers(values...)
+	}
+	return nil
+}
+
 func (m Migrator) CreateTable(values ...interface{}) error {
 	for _, value := range values {
-		m.TryQuotifyReservedWords(value)
 		m.TryRemoveOnUpdate(value)
 	}
-	return m.Migrator.CreateTable(values...)
+	if err := m.createSequences(values...); err != nil {
+		return err
+	}
+	if err := m.Migrator.CreateTable(values...); err != nil {
+		return err
+	}
+	if err := m.createColumnChecks(values...); err != nil {
+		return err
+	}
+	if m.Dialector.(Dialector).IdentityTrigger {
+		return m.createIdentityTriggers(values...)
+	}
+	return nil
+}
+
+// createIdentityTriggers backs the auto increment columns of values by a sequence and a BEFORE INSERT
+// trigger, adopting an identity column or a trigger filling in the column the table already has instead
+func (m Migrator) createIdentityTriggers(values ...interface{}) error {
+	for _, value := range values {
+		if err := m.RunWithValue(value, func(stmt *gorm.Statement) error {
+			for _, field := range stmt.Schema.Fields {
+				if !isIdentity(field) || m.isIdentityColumn(tableOf(stmt), field.DBName) || m.hasIdentityTrigger(tableOf(stmt), field.DBName) {
+					continue
+				}
+
+				// the sequence carries on after the rows the table already has
+				opts := m.Dialector.(Dialector).identityOptionsOf(field).SequenceOptions
+				if opts.StartWith == 0 {
+					if err := m.DB.Raw(
+						"SELECT NVL(MAX(?), 0) + 1 FROM ?", clause.Column{Name: field.DBName}, m.CurrentTable(stmt),
+					).Row().Scan(&opts.StartWith); err != nil {
+						return err
+					}
+				}
+
+				sequence := m.shorten(identitySequenceName(tableOf(stmt)))
+				if !m.HasSequence(sequence) {
+					if err := m.CreateSequence(sequence, opts); err != nil {
+						return err
+					}
+				}
+				column := stmt.Quote(field.DBName)
+				if err := m.DB.Exec(fmt.Sprintf(
+					"CREATE OR REPLACE TRIGGER %s BEFORE INSERT ON %s FOR EACH ROW WHEN (NEW.%s IS NULL) BEGIN :NEW.%s := %s.NEXTVAL; END;",
+					stmt.Quote(m.shorten(identityTriggerName(tableOf(stmt)))), stmt.Quote(tableOf(stmt)), column, column, stmt.Quote(sequence),
+				)).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// createColumnChecks adds the missing check constraints keeping the columns of values to the values of
+// their fields, where their data types take more, e.g. 0 and 1 for NUMBER(1) bools
+func (m Migrator) createColumnChecks(values ...interface{}) error {
+	for _, value := range values {
+		if err := m.RunWithValue(value, func(stmt *gorm.Statement) error {
+			for _, field := range stmt.Schema.Fields {
+				suffix, condition := m.Dialector.(Dialector).columnCheckOf(field)
+				if condition == "" {
+					continue
+				}
+				name := m.DB.NamingStrategy.CheckerName(tableOf(stmt), field.DBName+"_"+suffix)
+				if m.HasConstraint(value, name) {
+					continue
+				}
+				if err := m.DB.Exec(
+					"ALTER TABLE ? ADD CONSTRAINT ? CHECK ("+condition+")",
+					m.CurrentTable(stmt), clause.Column{Name: name}, clause.Column{Name: field.DBName},
+				).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isIdentityColumn reports whether column of table is an identity column, never the case before 12c
+func (m Migrator) isIdentityColumn(table, column string) bool {
+	var count int64
+	return m.DB.Raw(
+		"SELECT COUNT(*) FROM ALL_TAB_IDENTITY_COLUMNS WHERE OWNER = "+currentSchema+" AND TABLE_NAME = ? AND COLUMN_NAME = ?",
+		append(ownerOf(m.identifierOf(table)), m.identifierOf(column))...,
+	).Row().Scan(&count) == nil && count > 0
+}
+
+// hasIdentityTrigger reports whether table has a BEFORE INSERT row trigger filling in column, either the
+// one createIdentityTriggers names after the table or one of a legacy schema assigning the column, other
+// insert triggers, e.g. auditing ones, leave the column to be filled in
+func (m Migrator) hasIdentityTrigger(table, column string) bool {
+	rows, err := m.DB.Raw(
+		"SELECT TRIGGER_NAME, TRIGGER_BODY FROM ALL_TRIGGERS WHERE TABLE_OWNER = "+currentSchema+" AND TABLE_NAME = ? AND TRIGGER_TYPE = 'BEFORE EACH ROW' AND TRIGGERING_EVENT LIKE '%INSERT%'",
+		ownerOf(m.identifierOf(table))...,
+	).Rows()
+	if err != nil {
+		return false
+	}
+	defer rows.Close()
+
+	// the body is a LONG, which can only be matched once it is read, as in :NEW.ID := or INTO :NEW.ID
+	name := ownerOf(m.identifierOf(m.shorten(identityTriggerName(table))))[1]
+	newColumn := `:NEW\s*\.\s*(?:"` + regexp.QuoteMeta(m.identifierOf(column)) + `"|` + regexp.QuoteMeta(column) + `\b)`
+	assigns := regexp.MustCompile(`(?i)\bINTO\s+` + newColumn + `|` + newColumn + `\s*:=`)
+	for rows.Next() {
+		var trigger, body string
+		if rows.Scan(&trigger, &body) == nil && (trigger == name || assigns.MatchString(body)) {
+			return true
+		}
+	}
+	return false
+}
+
+// createSequences creates the missing sequences the fields of values draw from
+func (m Migrator) createSequences(values ...interface{}) error {
+	for _, value := range values {
+		if err := m.RunWithValue(value, func(stmt *gorm.Statement) error {
+			for _, field := range sequenceFields(stmt.Schema) {
+				if !m.HasSequence(sequenceOf(field)) {
+					if err := m.CreateSequence(sequenceOf(field), SequenceOptions{}); err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m Migrator) CreateSequence(name string, opts SequenceOptions) error {
+	return m.DB.Exec("CREATE SEQUENCE "+m.ifExists(true)+"?"+opts.sql(false), clause.Table{Name: name}).Error
+}
+
+// AlterSequence changes the options of sequence name, a new start value restarts it, which only
+// 18c can do
+func (m Migrator) AlterSequence(name string, opts SequenceOptions) error {
+	if opts.StartWith != 0 && !m.Dialector.(Dialector).VersionAtLeast(18, 0) {
+		return fmt.Errorf("%w: restarting sequence %s needs oracle 18c", gorm.ErrNotImplemented, name)
+	}
+	return m.DB.Exec("ALTER SEQUENCE ?"+opts.sql(true), clause.Table{Name: name}).Error
+}
+
+func (m Migrator) DropSequence(name string) error {
+	return m.DB.Exec("DROP SEQUENCE "+m.ifExists(false)+"?", clause.Table{Name: name}).Error
+}
+
+// identifierOf returns name as the data dictionary keeps it, where it is uppercase unless quoted
+func (m Migrator) identifierOf(name string) string {
+	if m.Dialector.(Dialector).PreserveCase {
+		return name
+	}
+	return strings.ToUpper(name)
+}
+
+// currentSchema matches the owner of a table qualified by its schema, or the current schema
+const currentSchema = "NVL(?, SYS_CONTEXT('USERENV', 'CURRENT_SCHEMA'))"
+
+// ownerOf splits the owner off a name qualified by its schema, nil standing for the current schema,
+// into the vars of currentSchema and the name
+func ownerOf(name string) []interface{} {
+	if owner, name, ok := strings.Cut(name, "."); ok {
+		return []interface{}{owner, name}
+	}
+	return []interface{}{nil, name}
+}
+
+// shorten cuts name down to the identifier limit the way the namer cuts the names it writes
+func (m Migrator) shorten(name string) string {
+	if namer, ok := m.DB.NamingStrategy.(Namer); ok {
+		return namer.shorten(name)
+	}
+	return name
+}
+
+// tableOf is the table of stmt qualified by its schema, which gorm splits off into the table expression
+func tableOf(stmt *gorm.Statement) string {
+	if stmt.Schema != nil && strings.HasSuffix(stmt.Schema.Table, "."+stmt.Table) {
+		return stmt.Schema.Table
+	}
+	return stmt.Table
+}
+
+// ifExists returns the IF EXISTS, or IF NOT EXISTS when not is set, DDL takes from 23ai on
+func (m Migrator) ifExists(not bool) string {
+	switch {
+	case !m.Dialector.(Dialector).VersionAtLeast(23, 0):
+		return ""
+	case not:
+		return "IF NOT EXISTS "
+	}
+	return "IF EXISTS "
+}
+
+func (m Migrator) HasSequence(name string) bool {
+	var count int64
+	return m.DB.Raw(
+		"SELECT COUNT(*) FROM ALL_SEQUENCES WHERE SEQUENCE_OWNER = "+currentSchema+" AND SEQUENCE_NAME = ?",
+		ownerOf(m.identifierOf(name))...,
+	).Row().Scan(&count) == nil && count > 0
 }
 
 func (m Migrator) DropTable(values ...interface{}) error {
@@ -33,9 +263,9 @@ func (m Migrator) DropTable(values ...interface{}) error {
 	for i := len(values) - 1; i >= 0; i-- {
 		value := values[i]
 		tx := m.DB.Session(&gorm.Session{})
-		if m.HasTable(value) {
+		if ifExists := m.ifExists(false); ifExists != "" || m.HasTable(value) {
 			if err := m.RunWithValue(value, func(stmt *gorm.Statement) error {
-				return tx.Exec("DROP TABLE ? CASCADE CONSTRAINTS", clause.Table{Name: stmt.Table}).Error
+				return tx.Exec("DROP TABLE "+ifExists+"? CASCADE CONSTRAINTS", m.CurrentTable(stmt)).Error
 			}); err != nil {
 				return err
 			}
@@ -48,7 +278,9 @@ func (m Migrator) HasTable(value interface{}) bool {
 	var count int64
 
 	m.RunWithValue(value, func(stmt *gorm.Statement) error {
-		return m.DB.Raw("SELECT COUNT(*) FROM USER_TABLES WHERE TABLE_NAME = ?", stmt.Table).Row().Scan(&count)
+		return m.DB.Raw(
+			"SELECT COUNT(*) FROM ALL_TABLES WHERE OWNER = "+currentSchema+" AND TABLE_NAME = ?", ownerOf(m.identifierOf(tableOf(stmt)))...,
+		).Row().Scan(&count)
 	})
 
 	return count > 0
@@ -61,7 +293,7 @@ func (m Migrator) RenameTable(oldName, newName interface{}) (err error) {
 		} else {
 			stmt := &gorm.Statement{DB: m.DB}
 			if err = stmt.Parse(name); err == nil {
-				result = stmt.Table
+				result = tableOf(stmt)
 			}
 		}
 		return
@@ -87,12 +319,24 @@ func (m Migrator) RenameTable(oldName, newName interface{}) (err error) {
 	).Error
 }
 
+func (m Migrator) FullDataTypeOf(field *schema.Field) clause.Expr {
+	// the default value of a yes/no field is written as it is kept
+	if isYesNo(field) && field.DefaultValueInterface != nil {
+		if value, err := (YesNo{}).Value(context.Background(), field, reflect.Value{}, field.DefaultValueInterface); err == nil {
+			defaultField := *field
+			defaultField.DefaultValueInterface = value
+			field = &defaultField
+		}
+	}
+	return m.Migrator.FullDataTypeOf(field)
+}
+
 func (m Migrator) AddColumn(value interface{}, field string) error {
 	return m.RunWithValue(value, func(stmt *gorm.Statement) error {
 		if field := stmt.Schema.LookUpField(field); field != nil {
 			return m.DB.Exec(
 				"ALTER TABLE ? ADD ? ?",
-				clause.Table{Name: stmt.Table}, clause.Column{Name: field.DBName}, m.DB.Migrator().FullDataTypeOf(field),
+				m.CurrentTable(stmt), clause.Column{Name: field.DBName}, m.DB.Migrator().FullDataTypeOf(field),
 			).Error
 		}
 		return fmt.Errorf("failed to look up field with name: %s", field)
@@ -111,7 +355,7 @@ func (m Migrator) DropColumn(value interface{}, name string) error {
 
 		return m.DB.Exec(
 			"ALTER TABLE ? DROP ?",
-			clause.Table{Name: stmt.Table},
+			m.CurrentTable(stmt),
 			clause.Column{Name: name},
 		).Error
 	})
@@ -124,11 +368,17 @@ func (m Migrator) AlterColumn(value interface{}, field string) error {
 
 	return m.RunWithValue(value, func(stmt *gorm.Statement) error {
 		if field := stmt.Schema.LookUpField(field); field != nil {
+			// the identity is only part of the type a column is created with, modifying a column
+			// can neither add nor drop one
+			dataType := m.FullDataTypeOf(field)
+			if isIdentity(field) {
+				dataType.SQL = strings.Replace(dataType.SQL, m.Dialector.(Dialector).identityOf(field), "", 1)
+			}
 			return m.DB.Exec(
 				"ALTER TABLE ? MODIFY ? ?",
-				clause.Table{Name: stmt.Table},
+				m.CurrentTable(stmt),
 				clause.Column{Name: field.DBName},
-				m.FullDataTypeOf(field),
+				dataType,
 			).Error
 		}
 		return fmt.Errorf("failed to look up field with name: %s", field)
@@ -138,7 +388,17 @@ func (m Migrator) AlterColumn(value interface{}, field string) error {
 func (m Migrator) HasColumn(value interface{}, field string) bool {
 	var count int64
 	return m.RunWithValue(value, func(stmt *gorm.Statement) error {
-		return m.DB.Raw("SELECT COUNT(*) FROM USER_TAB_COLUMNS WHERE TABLE_NAME = ? AND COLUMN_NAME = ?", stmt.Table, field).Row().Scan(&count)
+		name := field
+		if stmt.Schema != nil {
+			if f := stmt.Schema.LookUpField(field); f != nil {
+				name = f.DBName
+			}
+		}
+
+		return m.DB.Raw(
+			"SELECT COUNT(*) FROM ALL_TAB_COLUMNS WHERE OWNER = "+currentSchema+" AND TABLE_NAME = ? AND COLUMN_NAME = ?",
+			append(ownerOf(m.identifierOf(tableOf(stmt))), m.identifierOf(name))...,
+		).Row().Scan(&count)
 	}) == nil && count > 0
 }
 
@@ -153,14 +413,14 @@ func (m Migrator) DropConstraint(value interface{}, name string) error {
 			if chk.Name == name {
 				return m.DB.Exec(
 					"ALTER TABLE ? DROP CHECK ?",
-					clause.Table{Name: stmt.Table}, clause.Column{Name: name},
+					m.CurrentTable(stmt), clause.Column{Name: name},
 				).Error
 			}
 		}
 
 		return m.DB.Exec(
 			"ALTER TABLE ? DROP CONSTRAINT ?",
-			clause.Table{Name: stmt.Table}, clause.Column{Name: name},
+			m.CurrentTable(stmt), clause.Column{Name: m.shorten(name)},
 		).Error
 	})
 }
@@ -168,8 +428,19 @@ func (m Migrator) DropConstraint(value interface{}, name string) error {
 func (m Migrator) HasConstraint(value interface{}, name string) bool {
 	var count int64
 	return m.RunWithValue(value, func(stmt *gorm.Statement) error {
+		constraint, chk, table := m.GuessConstraintAndTable(stmt, name)
+		if constraint != nil {
+			name = constraint.Name
+		} else if chk != nil {
+			name = chk.Name
+		}
+		if table == stmt.Table {
+			table = tableOf(stmt)
+		}
+
 		return m.DB.Raw(
-			"SELECT COUNT(*) FROM USER_CONSTRAINTS WHERE TABLE_NAME = ? AND CONSTRAINT_NAME = ?", stmt.Table, name,
+			"SELECT COUNT(*) FROM ALL_CONSTRAINTS WHERE OWNER = "+currentSchema+" AND TABLE_NAME = ? AND CONSTRAINT_NAME = ?",
+			append(ownerOf(m.identifierOf(table)), m.identifierOf(m.shorten(name)))...,
 		).Row().Scan(&count)
 	}) == nil && count > 0
 }
@@ -180,7 +451,7 @@ func (m Migrator) DropIndex(value interface{}, name string) error {
 			name = idx.Name
 		}
 
-		return m.DB.Exec("DROP INDEX ?", clause.Column{Name: name}, clause.Table{Name: stmt.Table}).Error
+		return m.DB.Exec("DROP INDEX "+m.ifExists(false)+"?", clause.Column{Name: m.shorten(name)}, m.CurrentTable(stmt)).Error
 	})
 }
 
@@ -192,9 +463,8 @@ func (m Migrator) HasIndex(value interface{}, name string) bool {
 		}
 
 		return m.DB.Raw(
-			"SELECT COUNT(*) FROM USER_INDEXES WHERE TABLE_NAME = ? AND INDEX_NAME = ?",
-			m.Migrator.DB.NamingStrategy.TableName(stmt.Table),
-			m.Migrator.DB.NamingStrategy.IndexName(stmt.Table, name),
+			"SELECT COUNT(*) FROM ALL_INDEXES WHERE TABLE_OWNER = "+currentSchema+" AND TABLE_NAME = ? AND INDEX_NAME = ?",
+			append(ownerOf(m.identifierOf(tableOf(stmt))), m.identifierOf(m.shorten(name)))...,
 		).Row().Scan(&count)
 	})
 
@@ -203,11 +473,14 @@ func (m Migrator) HasIndex(value interface{}, name string) bool {
 
 // https://docs.oracle.com/database/121/SPATL/alter-index-rename.htm
 func (m Migrator) RenameIndex(value interface{}, oldName, newName string) error {
-	panic("TODO")
 	return m.RunWithValue(value, func(stmt *gorm.Statement) error {
+		if idx := stmt.Schema.LookIndex(oldName); idx != nil {
+			oldName = idx.Name
+		}
+
 		return m.DB.Exec(
-			"ALTER INDEX ?.? RENAME TO ?", // wat
-			clause.Table{Name: stmt.Table}, clause.Column{Name: oldName}, clause.Column{Name: newName},
+			"ALTER INDEX ? RENAME TO ?",
+			clause.Column{Name: m.shorten(oldName)}, clause.Column{Name: m.shorten(newName)},
 		).Error
 	})
 }
@@ -229,24 +502,7 @@ func (m Migrator) TryRemoveOnUpdate(values ...interface{}) error {
 	return nil
 }
 
+// Deprecated: QuoteTo quotes reserved words in every statement, the schema no longer needs them quoted
 func (m Migrator) TryQuotifyReservedWords(values ...interface{}) error {
-	for _, value := range values {
-		if err := m.RunWithValue(value, func(stmt *gorm.Statement) error {
-			for idx, v := range stmt.Schema.DBNames {
-				if IsReservedWord(v) {
-					stmt.Schema.DBNames[idx] = fmt.Sprintf(`"%s"`, v)
-				}
-			}
-
-			for _, v := range stmt.Schema.Fields {
-				if IsReservedWord(v.DBName) {
-					v.DBName = fmt.Sprintf(`"%s"`, v.DBName)
-				}
-			}
-			return nil
-		}); err != nil {
-			return err
-		}
-	}
 	return nil
 }
@@ -0,0 +1,35 @@
+package oracle
+
+import (
+	"database/sql/driver"
+	"testing"
+)
+
+func TestHasIdentityTrigger(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		trigger string
+		body    string
+		want    bool
+	}{
+		{"named after the table", "IDENTITY_ROWS_TRG", "BEGIN :NEW.ID := IDENTITY_ROWS_SEQ.NEXTVAL; END;", true},
+		{"assigning the column", "LEGACY_KEYS", "BEGIN\n  :new.id := legacy_seq.nextval;\nEND;", true},
+		{"assigning the quoted column", "LEGACY_KEYS", `BEGIN :NEW."ID" := LEGACY_SEQ.NEXTVAL; END;`, true},
+		{"selecting into the column", "LEGACY_KEYS", "BEGIN SELECT LEGACY_SEQ.NEXTVAL INTO :NEW.ID FROM DUAL; END;", true},
+		{"auditing", "AUDIT_ROWS", "BEGIN :NEW.CREATED_BY := USER; END;", false},
+		{"assigning another column", "LEGACY_KEYS", "BEGIN :NEW.IDX := 1; END;", false},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			db, r := recording(t, Config{})
+			r.results = []result{{columns: []string{"TRIGGER_NAME", "TRIGGER_BODY"}, rows: [][]driver.Value{{tt.trigger, tt.body}}}}
+			if got := db.Migrator().(Migrator).hasIdentityTrigger("IDENTITY_ROWS", "ID"); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	db, _ := recording(t, Config{})
+	if db.Migrator().(Migrator).hasIdentityTrigger("IDENTITY_ROWS", "ID") {
+		t.Errorf("a table without triggers has no identity trigger")
+	}
+}
@@ -1,38 +1,155 @@
 package oracle
 
 import (
-	"gorm.io/gorm/schema"
+	"crypto/sha1"
+	"encoding/hex"
 	"strings"
+	"unicode/utf8"
+
+	"gorm.io/gorm/schema"
+)
+
+// NameCase is the case Namer writes names in
+type NameCase int
+
+const (
+	// UpperCase uppercases names, as oracle does with unquoted identifiers
+	UpperCase NameCase = iota
+	// LowerCase lowercases names, which only stay lowercase with Config.PreserveCase set
+	LowerCase
+	// KeepCase leaves names as the wrapped naming strategy writes them
+	KeepCase
 )
 
+// Namer wraps the naming strategy configured with gorm, schema.NamingStrategy when there is none,
+// writing its names in Case and qualifying table names with Schema when it is set. Names of indexes
+// and constraints longer than IdentifierMaxLength are shortened, ending in a hash of the whole name
 type Namer struct {
-	schema.NamingStrategy
+	NamingStrategy schema.Namer
+	// Case is the case names are written in
+	Case NameCase
+	// Schema qualifies table and join table names, e.g. HR.USERS
+	Schema string
+	// IdentifierMaxLength is the length in bytes of identifiers, 30 before 12.2 and 128 from it on,
+	// which initialize sets from the server version unless it is given
+	IdentifierMaxLength int
+}
+
+// uniqueNamer is implemented by naming strategies naming unique constraints, which gorm's did not always
+type uniqueNamer interface {
+	UniqueName(table, column string) string
 }
 
 func ConvertNameToFormat(x string) string {
 	return strings.ToUpper(x)
 }
 
+func (n Namer) strategy() schema.Namer {
+	if n.NamingStrategy == nil {
+		return schema.NamingStrategy{}
+	}
+	return n.NamingStrategy
+}
+
+// format writes name in the case of n
+func (n Namer) format(name string) string {
+	switch n.Case {
+	case LowerCase:
+		return strings.ToLower(name)
+	case KeepCase:
+		return name
+	}
+	return ConvertNameToFormat(name)
+}
+
+// qualify prefixes table with the schema of n
+func (n Namer) qualify(table string) string {
+	if n.Schema == "" {
+		return table
+	}
+	return n.format(n.Schema) + "." + table
+}
+
+// unqualify strips the schema of n from table, the names of indexes and constraints go without it
+func (n Namer) unqualify(table string) string {
+	if n.Schema == "" {
+		return table
+	}
+	if owner, name, ok := strings.Cut(table, "."); ok && strings.EqualFold(owner, n.Schema) {
+		return name
+	}
+	return table
+}
+
+// shorten cuts name down to the identifier limit, ending it in a hash of the whole name so that it
+// stays unique and comes out the same every time, a schema it is qualified by is kept
+func (n Namer) shorten(name string) string {
+	maxLength := n.IdentifierMaxLength
+	if maxLength <= 0 {
+		maxLength = 128
+	}
+
+	var owner string
+	if idx := strings.LastIndexByte(name, '.'); idx >= 0 {
+		owner, name = name[:idx+1], name[idx+1:]
+	}
+	if len(name) <= maxLength {
+		return owner + name
+	}
+
+	sum := sha1.Sum([]byte(name))
+	hash := n.format(hex.EncodeToString(sum[:])[:8])
+	cut := maxLength - len(hash)
+	for cut > 0 && !utf8.RuneStart(name[cut]) {
+		cut--
+	}
+	return owner + name[:cut] + hash
+}
+
 func (n Namer) TableName(table string) (name string) {
-	return ConvertNameToFormat(n.NamingStrategy.TableName(table))
+	return n.qualify(n.format(n.strategy().TableName(table)))
+}
+
+// SchemaName turns a table name back into the name of a model, the way the wrapped strategy does
+// with the names it writes itself
+func (n Namer) SchemaName(table string) string {
+	table = n.unqualify(table)
+	if n.Case == UpperCase {
+		table = strings.ToLower(table)
+	}
+	return n.strategy().SchemaName(table)
 }
 
 func (n Namer) ColumnName(table, column string) (name string) {
-	return ConvertNameToFormat(n.NamingStrategy.ColumnName(table, column))
+	return n.format(n.strategy().ColumnName(n.unqualify(table), column))
 }
 
 func (n Namer) JoinTableName(table string) (name string) {
-	return ConvertNameToFormat(n.NamingStrategy.JoinTableName(table))
+	return n.qualify(n.format(n.strategy().JoinTableName(table)))
 }
 
 func (n Namer) RelationshipFKName(relationship schema.Relationship) (name string) {
-	return ConvertNameToFormat(n.NamingStrategy.RelationshipFKName(relationship))
+	if relationship.Schema != nil && n.Schema != "" {
+		s := *relationship.Schema
+		s.Table = n.unqualify(s.Table)
+		relationship.Schema = &s
+	}
+	return n.shorten(n.format(n.strategy().RelationshipFKName(relationship)))
 }
 
 func (n Namer) CheckerName(table, column string) (name string) {
-	return ConvertNameToFormat(n.NamingStrategy.CheckerName(table, column))
+	return n.shorten(n.format(n.strategy().CheckerName(n.unqualify(table), column)))
 }
 
 func (n Namer) IndexName(table, column string) (name string) {
-	return ConvertNameToFormat(n.NamingStrategy.IndexName(table, column))
+	return n.shorten(n.format(n.strategy().IndexName(n.unqualify(table), column)))
+}
+
+// UniqueName names the unique constraint of column, derived from its index name for strategies
+// that do not name them
+func (n Namer) UniqueName(table, column string) (name string) {
+	if namer, ok := n.strategy().(uniqueNamer); ok {
+		return n.shorten(n.format(namer.UniqueName(n.unqualify(table), column)))
+	}
+	return n.shorten(n.format("uni_" + strings.TrimPrefix(n.strategy().IndexName(n.unqualify(table), column), "idx_")))
 }
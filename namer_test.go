@@ -0,0 +1,84 @@
+package oracle
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"gorm.io/gorm/schema"
+)
+
+func TestNamerShorten(t *testing.T) {
+	namer := Namer{IdentifierMaxLength: 30}
+
+	if name := namer.shorten("IDX_USERS_NAME"); name != "IDX_USERS_NAME" {
+		t.Errorf("a name within the limit should be left alone, got %s", name)
+	}
+
+	long := "IDX_CUSTOMER_ORDERS_SHIPPING_ADDRESS_ID"
+	name := namer.shorten(long)
+	if len(name) != 30 {
+		t.Errorf("%s should be cut to 30 bytes, got %d", name, len(name))
+	}
+	if !strings.HasPrefix(name, long[:22]) {
+		t.Errorf("%s should start with the name it was cut from", name)
+	}
+	if again := namer.shorten(long); again != name {
+		t.Errorf("shortening should come out the same every time, got %s and %s", name, again)
+	}
+	if other := namer.shorten(long + "_2"); other == name {
+		t.Errorf("names sharing a prefix should stay apart, both got %s", name)
+	}
+
+	if name := namer.shorten("HR." + long); !strings.HasPrefix(name, "HR.") || len(name) != len("HR.")+30 {
+		t.Errorf("the schema should be kept and the name cut, got %s", name)
+	}
+
+	// the cut falls on a rune boundary
+	if name := namer.shorten("IDX_" + strings.Repeat("é", 20)); !utf8.ValidString(name) || len(name) > 30 {
+		t.Errorf("%q should be valid UTF-8 of at most 30 bytes", name)
+	}
+
+	if name := (Namer{}).shorten(strings.Repeat("A", 128)); len(name) != 128 {
+		t.Errorf("the limit should default to 128 bytes, got a name of %d", len(name))
+	}
+	if name := (Namer{Case: LowerCase, IdentifierMaxLength: 30}).shorten(strings.ToLower(long)); name != strings.ToLower(name) {
+		t.Errorf("the hash should be written in the case of the namer, got %s", name)
+	}
+}
+
+func TestNamerNames(t *testing.T) {
+	for _, tt := range []struct {
+		name  string
+		namer Namer
+		got   func(Namer) string
+		want  string
+	}{
+		{"table", Namer{}, func(n Namer) string { return n.TableName("UserAccount") }, "USER_ACCOUNTS"},
+		{"lowercase table", Namer{Case: LowerCase}, func(n Namer) string { return n.TableName("UserAccount") }, "user_accounts"},
+		{"kept case table", Namer{Case: KeepCase}, func(n Namer) string { return n.TableName("UserAccount") }, "user_accounts"},
+		{"qualified table", Namer{Schema: "hr"}, func(n Namer) string { return n.TableName("UserAccount") }, "HR.USER_ACCOUNTS"},
+		{"qualified join table", Namer{Schema: "hr"}, func(n Namer) string { return n.JoinTableName("user_roles") }, "HR.USER_ROLES"},
+		{"schema name", Namer{Schema: "hr"}, func(n Namer) string { return n.SchemaName("HR.USER_ACCOUNTS") }, "UserAccount"},
+		{"column", Namer{}, func(n Namer) string { return n.ColumnName("HR.USERS", "CreatedAt") }, "CREATED_AT"},
+		{"index", Namer{Schema: "hr"}, func(n Namer) string { return n.IndexName("HR.USERS", "Name") }, "IDX_USERS_NAME"},
+		{"checker", Namer{}, func(n Namer) string { return n.CheckerName("USERS", "ACTIVE_bool") }, "CHK_USERS_ACTIVE_BOOL"},
+		{"unique", Namer{}, func(n Namer) string { return n.UniqueName("USERS", "EMAIL") }, "UNI_USERS_EMAIL"},
+		{
+			"wrapped strategy",
+			Namer{NamingStrategy: schema.NamingStrategy{TablePrefix: "app_", SingularTable: true}},
+			func(n Namer) string { return n.TableName("UserAccount") },
+			"APP_USER_ACCOUNT",
+		},
+		{
+			"shortened index",
+			Namer{IdentifierMaxLength: 30},
+			func(n Namer) string { return n.IndexName("CUSTOMER_ORDERS", "ShippingAddressID") },
+			Namer{IdentifierMaxLength: 30}.shorten("IDX_CUSTOMER_ORDERS_SHIPPING_ADDRESS_ID"),
+		},
+	} {
+		if got := tt.got(tt.namer); got != tt.want {
+			t.Errorf("%s: got %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package oracle
+
+import (
+	"fmt"
+
+	"gorm.io/gorm/schema"
+)
+
+// numberOf returns the data type of a numeric field, NUMBER(p,s) after its precision and scale tags,
+// e.g. gorm:"precision:12;scale:2", NUMBER(p) holding every value of its integer type otherwise, and
+// FLOAT, or BINARY_FLOAT and BINARY_DOUBLE with Config.BinaryFloat set, for floats
+func (d Dialector) numberOf(field *schema.Field) string {
+	switch {
+	case field.Precision > 0 && field.Scale > 0:
+		return fmt.Sprintf("NUMBER(%d,%d)", field.Precision, field.Scale)
+	case field.Precision > 0:
+		return fmt.Sprintf("NUMBER(%d)", field.Precision)
+	case field.Scale > 0:
+		return fmt.Sprintf("NUMBER(*,%d)", field.Scale)
+	}
+
+	switch field.DataType {
+	case schema.Int, schema.Uint:
+		return fmt.Sprintf("NUMBER(%d)", integerPrecisionOf(field))
+	}
+	if d.BinaryFloat {
+		if field.Size == 32 {
+			return "BINARY_FLOAT"
+		}
+		return "BINARY_DOUBLE"
+	}
+	return "FLOAT"
+}
+
+// integerPrecisionOf returns the decimal digits of the largest value of an integer field by its
+// size in bits, e.g. 3 for int8 and 19 for int64, where uint64 takes 20
+func integerPrecisionOf(field *schema.Field) int {
+	switch {
+	case field.Size <= 8:
+		return 3
+	case field.Size <= 16:
+		return 5
+	case field.Size <= 32:
+		return 10
+	case field.DataType == schema.Uint:
+		return 20
+	}
+	return 19
+}
@@ -1,9 +1,9 @@
 package oracle
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
-	"gorm.io/gorm/utils"
 	"regexp"
 	"strconv"
 	"strings"
@@ -16,6 +16,9 @@ import (
 	"gorm.io/gorm/logger"
 	"gorm.io/gorm/migrator"
 	"gorm.io/gorm/schema"
+	"gorm.io/gorm/utils"
+
+	"github.com/rahmanme/oracle/clauses"
 )
 
 type Config struct {
@@ -23,8 +26,49 @@ type Config struct {
 	DSN               string
 	Conn              *sql.DB
 	DefaultStringSize uint
+	// ServerVersion is the version of the server, e.g. 19.0.0.0.0, asked for on initialize unless
+	// it is given or SkipInitializeWithVersion is set, in which case 12.1 is assumed
+	ServerVersion             string
+	SkipInitializeWithVersion bool
+	// ImplicitOrderBy is what a paginated query without an ORDER BY is ordered by
+	ImplicitOrderBy ImplicitOrderBy
+	// PreserveCase quotes every identifier, keeping its case, where they are left unquoted for oracle
+	// to uppercase them otherwise
+	PreserveCase bool
+	// LoadReservedWords adds the reserved words of V$RESERVED_WORDS to the ones quoted, on initialize
+	LoadReservedWords bool
+	reservedWords     map[string]bool
+	// Retry runs statements and transactions failing with a transient error again, nil never does
+	Retry *RetryPolicy
+	// Identity are the options of columns tagged autoIncrement or identity, fields override them by tag
+	Identity IdentityOptions
+	// BinaryFloat maps float fields to BINARY_FLOAT and BINARY_DOUBLE, IEEE 754 like go's, rather than
+	// to FLOAT, fields with a precision or scale tag are NUMBER either way
+	BinaryFloat bool
+	// TimeType is the data type of time fields, TIMESTAMP WITH TIME ZONE unless it is given, fields
+	// override it by tag
+	TimeType TimeType
+	// SessionTimeZone is set as the TIME_ZONE of every session, e.g. UTC or +02:00, and is the location
+	// times without a time zone are read back in. It applies to the pool opened from DSN, a Conn given
+	// is left as it is
+	SessionTimeZone string
+	// IdentityTrigger backs columns tagged autoIncrement or identity by a sequence and a BEFORE INSERT trigger
+	// rather than an identity, for databases predating identity columns (11g)
+	IdentityTrigger bool
 }
 
+// ImplicitOrderBy is what a paginated query without an ORDER BY is ordered by
+type ImplicitOrderBy int
+
+const (
+	// OrderByPrimaryKey orders by the prioritized primary key, or by nothing in particular without one
+	OrderByPrimaryKey ImplicitOrderBy = iota
+	// OrderByPrimaryKeys orders by all the primary keys, as composite keys need
+	OrderByPrimaryKeys
+	// OrderByNone leaves the query unordered
+	OrderByNone
+)
+
 type Dialector struct {
 	*Config
 }
@@ -41,12 +85,41 @@ func (d Dialector) DummyTableName() string {
 	return "DUAL"
 }
 
+// FromDual returns the FROM clause of a SELECT without a table, which 23ai no longer needs
+func (d Dialector) FromDual() string {
+	if d.VersionAtLeast(23, 0) {
+		return ""
+	}
+	return " FROM " + d.DummyTableName()
+}
+
+// identifierMaxLength is the length in bytes of identifiers, which 12.2 raised from 30 to 128
+func (d Dialector) identifierMaxLength() int {
+	if d.VersionAtLeast(12, 2) {
+		return 128
+	}
+	return 30
+}
+
+// VersionAtLeast reports whether the server is at least of version major.minor
+func (d Dialector) VersionAtLeast(major, minor int) bool {
+	version := [2]int{12, 1}
+	if d.Config != nil && d.ServerVersion != "" {
+		version = [2]int{}
+		for idx, part := range strings.SplitN(d.ServerVersion, ".", 3) {
+			if idx < len(version) {
+				version[idx], _ = strconv.Atoi(part)
+			}
+		}
+	}
+	return version[0] > major || version[0] == major && version[1] >= minor
+}
+
 func (d Dialector) Name() string {
 	return "oracle"
 }
 
 func (d Dialector) Initialize(db *gorm.DB) (err error) {
-	db.NamingStrategy = Namer{}
 	d.DefaultStringSize = 1024
 
 	// register callbacks
@@ -56,14 +129,101 @@ func (d Dialector) Initialize(db *gorm.DB) (err error) {
 
 	if d.Conn != nil {
 		db.ConnPool = d.Conn
+	} else if d.SessionTimeZone != "" {
+		db.ConnPool, err = d.openWithSessionTimeZone()
 	} else {
 		db.ConnPool, err = sql.Open(d.DriverName, d.DSN)
 	}
+	if err != nil {
+		return
+	}
+	if sqlDB, ok := db.ConnPool.(*sql.DB); ok {
+		db.ConnPool = &connPool{DB: sqlDB, retry: d.Retry}
+	}
+
+	if d.ServerVersion == "" && !d.SkipInitializeWithVersion {
+		if err = db.ConnPool.QueryRowContext(
+			context.Background(), "SELECT VERSION FROM PRODUCT_COMPONENT_VERSION WHERE PRODUCT LIKE 'Oracle%' AND ROWNUM = 1",
+		).Scan(&d.ServerVersion); err != nil {
+			return
+		}
+	}
+	if d.LoadReservedWords {
+		if err = d.loadReservedWords(db); err != nil {
+			return
+		}
+	}
+	// the naming strategy configured with gorm keeps its settings, wrapped to write oracle names
+	namer, ok := db.NamingStrategy.(Namer)
+	if ptr, isPtr := db.NamingStrategy.(*Namer); isPtr {
+		namer, ok = *ptr, true
+	}
+	if !ok {
+		namer = Namer{NamingStrategy: db.NamingStrategy}
+	}
+	if namer.IdentifierMaxLength == 0 {
+		namer.IdentifierMaxLength = d.identifierMaxLength()
+	}
+	db.NamingStrategy = namer
+
+	// identity columns came with 12.1
+	if !d.VersionAtLeast(12, 1) {
+		d.IdentityTrigger = true
+	}
 
 	if err = db.Callback().Create().Replace("gorm:create", Create); err != nil {
 		return
 	}
 
+	// updates and deletes build their RETURNING clause as RETURNING ... INTO, but still execute like
+	// statements without one, the out binds are scanned back afterwards
+	if err = db.Callback().Update().Before("gorm:update").Register("oracle:prepare_returning", PrepareReturning); err != nil {
+		return
+	}
+	if err = db.Callback().Update().After("gorm:update").Register("oracle:scan_returning", ScanReturning); err != nil {
+		return
+	}
+	if err = db.Callback().Update().Before("gorm:update").Register("oracle:serialize_yesno", SerializeYesNo); err != nil {
+		return
+	}
+	if err = db.Callback().Update().After("gorm:update").Register("oracle:reset_yesno", ResetYesNo); err != nil {
+		return
+	}
+	if err = db.Callback().Delete().Before("gorm:delete").Register("oracle:prepare_returning", PrepareReturning); err != nil {
+		return
+	}
+
+	// conditions of maps and structs on yesno fields compare them to 'Y' and 'N'
+	if err = db.Callback().Query().Before("gorm:query").Register("oracle:serialize_yesno_conditions", SerializeYesNoConditions); err != nil {
+		return
+	}
+	if err = db.Callback().Row().Before("gorm:row").Register("oracle:serialize_yesno_conditions", SerializeYesNoConditions); err != nil {
+		return
+	}
+	if err = db.Callback().Update().Before("gorm:update").Register("oracle:serialize_yesno_conditions", SerializeYesNoConditions); err != nil {
+		return
+	}
+	if err = db.Callback().Delete().Before("gorm:delete").Register("oracle:serialize_yesno_conditions", SerializeYesNoConditions); err != nil {
+		return
+	}
+	if err = db.Callback().Delete().After("gorm:delete").Register("oracle:scan_returning", ScanReturning); err != nil {
+		return
+	}
+	if err = db.Callback().Raw().Before("gorm:raw").Register("oracle:merge", Merge); err != nil {
+		return
+	}
+	if err = db.Callback().Create().After("gorm:create").Register("oracle:resolve_error", ResolveError); err != nil {
+		return
+	}
+	if err = db.Callback().Update().After("gorm:update").Register("oracle:resolve_error", ResolveError); err != nil {
+		return
+	}
+	if err = db.Callback().Delete().After("gorm:delete").Register("oracle:resolve_error", ResolveError); err != nil {
+		return
+	}
+	db.Callback().Update().Clauses = append(db.Callback().Update().Clauses, "RETURNING")
+	db.Callback().Delete().Clauses = append(db.Callback().Delete().Clauses, "RETURNING")
+
 	for k, v := range d.ClauseBuilders() {
 		db.ClauseBuilders[k] = v
 	}
@@ -77,37 +237,170 @@ func (d Dialector) ClauseBuilders() map[string]clause.ClauseBuilder {
 }
 
 func (d Dialector) RewriteLimit(c clause.Clause, builder clause.Builder) {
-	if limit, ok := c.Expression.(clause.Limit); ok {
-		if stmt, ok := builder.(*gorm.Statement); ok {
-			if _, ok := stmt.Clauses["ORDER BY"]; !ok {
-				s := stmt.Schema
-				builder.WriteString("ORDER BY ")
-				if s != nil && s.PrioritizedPrimaryField != nil {
-					builder.WriteQuoted(s.PrioritizedPrimaryField.DBName)
-					builder.WriteByte(' ')
-				} else {
-					builder.WriteString("(SELECT NULL FROM ")
-					builder.WriteString(d.DummyTableName())
-					builder.WriteString(")")
+	if fetch, ok := c.Expression.(clauses.FetchFirst); ok {
+		// ROWNUM can stand in for the number of rows, but not for a percentage or ties
+		if !d.VersionAtLeast(12, 1) && (fetch.Percent > 0 || fetch.WithTies) {
+			builder.AddError(fmt.Errorf("%w: FETCH FIRST PERCENT and WITH TIES need oracle 12c", gorm.ErrNotImplemented))
+			return
+		}
+		if d.VersionAtLeast(12, 1) {
+			if stmt, ok := builder.(*gorm.Statement); ok {
+				if _, ok := stmt.Clauses["ORDER BY"]; !ok && d.writeImplicitOrderBy(stmt) && (fetch.Offset > 0 || fetch.Rows > 0 || fetch.Percent > 0) {
+					stmt.WriteByte(' ')
 				}
 			}
+			fetch.Build(builder)
+			return
+		}
+
+		rows := fetch.Rows
+		c.Expression = clause.Limit{Offset: fetch.Offset, Limit: &rows}
+		if rows <= 0 {
+			c.Expression = clause.Limit{Offset: fetch.Offset}
+		}
+	}
+
+	limit, ok := c.Expression.(clause.Limit)
+	if !ok {
+		return
+	}
+	hasLimit := limit.Limit != nil && *limit.Limit >= 0
+	if !hasLimit && limit.Offset <= 0 {
+		return
+	}
+
+	// the clause is preceded by a space already, which separates the first thing it writes
+	var separator string
+	if stmt, ok := builder.(*gorm.Statement); ok {
+		if _, ok := stmt.Clauses["ORDER BY"]; !ok && d.writeImplicitOrderBy(stmt) {
+			separator = " "
+		}
+
+		// OFFSET and FETCH came with 12.1, older servers page over ROWNUM, selecting from a view
+		// that FOR UPDATE can not lock (ORA-02014)
+		if !d.VersionAtLeast(12, 1) {
+			if _, ok := stmt.Clauses["FOR"]; ok {
+				stmt.AddError(fmt.Errorf("%w: locking rows paged by ROWNUM", gorm.ErrNotImplemented))
+				return
+			}
+			d.rewriteRownum(stmt, limit)
+			return
 		}
+	}
 
-		if offset := limit.Offset; offset > 0 {
-			builder.WriteString(" OFFSET ")
-			builder.WriteString(strconv.Itoa(offset))
-			builder.WriteString(" ROWS")
+	if offset := limit.Offset; offset > 0 {
+		builder.WriteString(separator)
+		builder.WriteString("OFFSET ")
+		builder.WriteString(strconv.Itoa(offset))
+		builder.WriteString(" ROWS")
+		separator = " "
+	}
+	if hasLimit {
+		builder.WriteString(separator)
+		builder.WriteString("FETCH NEXT ")
+		builder.WriteString(strconv.Itoa(*limit.Limit))
+		builder.WriteString(" ROWS ONLY")
+	}
+}
+
+// writeImplicitOrderBy orders a paginated query without an ORDER BY of its own, so that its pages
+// do not overlap, reporting whether it wrote one
+func (d Dialector) writeImplicitOrderBy(stmt *gorm.Statement) bool {
+	var fields []*schema.Field
+	if s := stmt.Schema; s != nil {
+		switch d.ImplicitOrderBy {
+		case OrderByNone:
+			return false
+		case OrderByPrimaryKeys:
+			fields = s.PrimaryFields
+		default:
+			if s.PrioritizedPrimaryField != nil {
+				fields = []*schema.Field{s.PrioritizedPrimaryField}
+			}
 		}
-		if l := *limit.Limit; l > 0 {
-			builder.WriteString(" FETCH NEXT ")
-			builder.WriteString(strconv.Itoa(l))
-			builder.WriteString(" ROWS ONLY")
+	} else if d.ImplicitOrderBy == OrderByNone {
+		return false
+	}
+
+	stmt.WriteString("ORDER BY ")
+	if len(fields) == 0 {
+		stmt.WriteString("(SELECT NULL")
+		stmt.WriteString(d.FromDual())
+		stmt.WriteString(")")
+		return true
+	}
+	for idx, field := range fields {
+		if idx > 0 {
+			stmt.WriteByte(',')
+		}
+		stmt.WriteQuoted(clause.Column{Table: stmt.Table, Name: field.DBName})
+	}
+	return true
+}
+
+// rewriteRownum wraps the query built so far to return the rows of limit by their ROWNUM, the
+// inner query keeps its order as ROWNUM is assigned after it
+func (d Dialector) rewriteRownum(stmt *gorm.Statement, limit clause.Limit) {
+	query := strings.TrimSpace(stmt.SQL.String())
+	stmt.SQL.Reset()
+
+	if limit.Offset <= 0 {
+		stmt.WriteString("SELECT * FROM (")
+		stmt.WriteString(query)
+		stmt.WriteString(") WHERE ROWNUM <= ")
+		stmt.WriteString(strconv.Itoa(*limit.Limit))
+		return
+	}
+
+	// the RN column paging goes by is left out of the rows returned, the selected columns are
+	// projected when they are known, a query selecting * or expressions returns it along with them
+	stmt.WriteString("SELECT ")
+	writeProjection(stmt)
+	stmt.WriteString(" FROM (SELECT a.*, ROWNUM RN FROM (")
+	stmt.WriteString(query)
+	stmt.WriteString(") a")
+	if limit.Limit != nil && *limit.Limit >= 0 {
+		stmt.WriteString(" WHERE ROWNUM <= ")
+		stmt.WriteString(strconv.Itoa(limit.Offset + *limit.Limit))
+	}
+	stmt.WriteString(") WHERE RN > ")
+	stmt.WriteString(strconv.Itoa(limit.Offset))
+}
+
+// writeProjection writes the names of the columns selected by stmt as they come out of its query,
+// or * when they are not all named columns
+func writeProjection(stmt *gorm.Statement) {
+	selects, _ := stmt.Clauses["SELECT"].Expression.(clause.Select)
+	names := make([]string, 0, len(selects.Columns))
+	for _, column := range selects.Columns {
+		name := column.Alias
+		if name == "" {
+			name = column.Name[strings.LastIndexByte(column.Name, '.')+1:]
+			// raw columns are only known by name when they are one, e.g. Select("name"), IsValidDBNameChar
+			// is true for the characters separating names
+			if column.Raw && strings.IndexFunc(column.Name, utils.IsValidDBNameChar) >= 0 {
+				name = ""
+			}
+		}
+		if name == "" || name == "*" {
+			break
+		}
+		names = append(names, name)
+	}
+	if len(names) == 0 || len(names) < len(selects.Columns) {
+		stmt.WriteByte('*')
+		return
+	}
+	for idx, name := range names {
+		if idx > 0 {
+			stmt.WriteByte(',')
 		}
+		stmt.WriteQuoted(clause.Column{Name: name})
 	}
 }
 
 func (d Dialector) DefaultValueOf(*schema.Field) clause.Expression {
-	return clause.Expr{SQL: "VALUES (DEFAULT)"}
+	return clause.Expr{SQL: "DEFAULT"}
 }
 
 func (d Dialector) Migrator(db *gorm.DB) gorm.Migrator {
@@ -122,29 +415,72 @@ func (d Dialector) Migrator(db *gorm.DB) gorm.Migrator {
 	}
 }
 
+// BindVarTo writes the placeholder of the var added last, binding a bool as 1/0 whichever clause or
+// expression it was added by
 func (d Dialector) BindVarTo(writer clause.Writer, stmt *gorm.Statement, v interface{}) {
+	if last := len(stmt.Vars) - 1; last >= 0 {
+		stmt.Vars[last] = bindBool(stmt.Vars[last])
+	}
 	writer.WriteString(":")
 	writer.WriteString(strconv.Itoa(len(stmt.Vars)))
 }
 
+// unquotedIdentifier matches the identifiers that can go unquoted, leaving reserved words aside
+var unquotedIdentifier = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_$#]*$`)
+
+// QuoteTo writes the identifier str, each part of a dotted name on its own. Parts are left unquoted for
+// oracle to uppercase them unless they can not go unquoted or are reserved words, or are all quoted keeping
+// their case with PreserveCase set, a part that is quoted already, a * or a placeholder is written as it is
 func (d Dialector) QuoteTo(writer clause.Writer, str string) {
-	writer.WriteString(str)
+	for idx, part := range splitIdentifier(str) {
+		if idx > 0 {
+			writer.WriteByte('.')
+		}
+
+		switch {
+		case part == "*" || strings.HasPrefix(part, ":"),
+			len(part) > 1 && strings.HasPrefix(part, `"`) && strings.HasSuffix(part, `"`):
+			writer.WriteString(part)
+		case d.PreserveCase || !unquotedIdentifier.MatchString(part):
+			writeQuoted(writer, part)
+		case d.isReservedWord(strings.ToUpper(part)):
+			// uppercase, as oracle would have taken it unquoted
+			writeQuoted(writer, strings.ToUpper(part))
+		default:
+			writer.WriteString(part)
+		}
+	}
 }
 
-var numericPlaceholder = regexp.MustCompile(`:(\d+)`)
+// writeQuoted writes str in double quotes, doubling the ones within it
+func writeQuoted(writer clause.Writer, str string) {
+	writer.WriteByte('"')
+	writer.WriteString(strings.ReplaceAll(str, `"`, `""`))
+	writer.WriteByte('"')
+}
 
-func (d Dialector) Explain(sql string, vars ...interface{}) string {
-	return logger.ExplainSQL(sql, numericPlaceholder, `'`, funk.Map(vars, func(v interface{}) interface{} {
-		switch v := v.(type) {
-		case bool:
-			if v {
-				return 1
+// splitIdentifier splits a dotted name into its parts, leaving the dots within quotes alone
+func splitIdentifier(str string) (parts []string) {
+	var quoted bool
+	start := 0
+	for idx, c := range str {
+		switch c {
+		case '"':
+			quoted = !quoted
+		case '.':
+			if !quoted {
+				parts = append(parts, str[start:idx])
+				start = idx + 1
 			}
-			return 0
-		default:
-			return v
 		}
-	}).([]interface{})...)
+	}
+	return append(parts, str[start:])
+}
+
+var numericPlaceholder = regexp.MustCompile(`:(\d+)`)
+
+func (d Dialector) Explain(sql string, vars ...interface{}) string {
+	return logger.ExplainSQL(sql, numericPlaceholder, `'`, funk.Map(vars, bindBool).([]interface{})...)
 }
 
 func (d Dialector) DataTypeOf(field *schema.Field) string {
@@ -152,21 +488,22 @@ func (d Dialector) DataTypeOf(field *schema.Field) string {
 		delete(field.TagSettings, "RESTRICT")
 	}
 
+	if isYesNo(field) {
+		return "CHAR(1)"
+	}
+
 	var sqlType string
 
 	switch field.DataType {
-	case schema.Bool, schema.Int, schema.Uint, schema.Float:
-		sqlType = "INTEGER"
-
-		switch {
-		case field.DataType == schema.Float:
-			sqlType = "FLOAT"
-		case field.Size <= 8:
-			sqlType = "SMALLINT"
+	case schema.Bool:
+		sqlType = "NUMBER(1)"
+		if d.VersionAtLeast(23, 0) {
+			sqlType = "BOOLEAN"
 		}
-
-		if val, ok := field.TagSettings["AUTOINCREMENT"]; ok && utils.CheckTruth(val) {
-			sqlType += " GENERATED BY DEFAULT AS IDENTITY"
+	case schema.Int, schema.Uint, schema.Float:
+		sqlType = d.numberOf(field)
+		if isIdentity(field) && !d.IdentityTrigger {
+			sqlType += d.identityOf(field)
 		}
 	case schema.String, "VARCHAR2":
 		size := field.Size
@@ -191,7 +528,7 @@ func (d Dialector) DataTypeOf(field *schema.Field) string {
 		}
 
 	case schema.Time:
-		sqlType = "TIMESTAMP WITH TIME ZONE"
+		sqlType = d.timeTypeOf(field)
 		if field.NotNull || field.PrimaryKey {
 			sqlType += " NOT NULL"
 		}
@@ -225,12 +562,44 @@ func (d Dialector) DataTypeOf(field *schema.Field) string {
 	return sqlType
 }
 
+// columnCheckOf returns the condition, with a ? for the column, that the column of field is checked
+// against where its data type takes more than the values of the field, and the suffix naming the check
+func (d Dialector) columnCheckOf(field *schema.Field) (suffix, condition string) {
+	switch {
+	case field.DBName == "":
+	case isYesNo(field):
+		return "bool", "? IN ('Y', 'N')"
+	case field.DataType == schema.Bool && !d.VersionAtLeast(23, 0):
+		return "bool", "? IN (0, 1)"
+	case field.DataType == schema.Uint:
+		return "unsigned", "? >= 0"
+	}
+	return "", ""
+}
+
+// savePointName matches the names a savepoint can be given without quoting them
+var savePointName = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_$#]{0,127}$`)
+
+// quoteSavePoint quotes the savepoint name, refusing any that is no plain identifier
+func quoteSavePoint(name string) (string, error) {
+	if !savePointName.MatchString(name) {
+		return "", fmt.Errorf("invalid savepoint name %q", name)
+	}
+	return `"` + name + `"`, nil
+}
+
 func (d Dialector) SavePoint(tx *gorm.DB, name string) error {
-	tx.Exec("SAVEPOINT " + name)
-	return tx.Error
+	name, err := quoteSavePoint(name)
+	if err != nil {
+		return err
+	}
+	return tx.Exec("SAVEPOINT " + name).Error
 }
 
 func (d Dialector) RollbackTo(tx *gorm.DB, name string) error {
-	tx.Exec("ROLLBACK TO SAVEPOINT " + name)
-	return tx.Error
+	name, err := quoteSavePoint(name)
+	if err != nil {
+		return err
+	}
+	return tx.Exec("ROLLBACK TO SAVEPOINT " + name).Error
 }
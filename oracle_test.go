@@ -0,0 +1,312 @@
+package oracle
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/rahmanme/oracle/clauses"
+)
+
+// dryRun opens a dialector that only builds statements, no server or client library is needed
+func dryRun(t *testing.T, config Config) *gorm.DB {
+	t.Helper()
+	if config.ServerVersion == "" {
+		config.ServerVersion = "19.0.0.0.0"
+	}
+	db, err := gorm.Open(New(config), &gorm.Config{DryRun: true, DisableAutomaticPing: true, SkipDefaultTransaction: true})
+	if err != nil {
+		t.Fatalf("failed to open dialector, got error %v", err)
+	}
+	return db
+}
+
+func TestSplitIdentifier(t *testing.T) {
+	for _, tt := range []struct {
+		name  string
+		parts []string
+	}{
+		{"USERS", []string{"USERS"}},
+		{"HR.USERS", []string{"HR", "USERS"}},
+		{`"a.b".C`, []string{`"a.b"`, "C"}},
+		{"HR.USERS.*", []string{"HR", "USERS", "*"}},
+	} {
+		if parts := splitIdentifier(tt.name); strings.Join(parts, "|") != strings.Join(tt.parts, "|") {
+			t.Errorf("splitIdentifier(%q) = %q, want %q", tt.name, parts, tt.parts)
+		}
+	}
+}
+
+func TestQuoteTo(t *testing.T) {
+	for _, tt := range []struct {
+		name         string
+		preserveCase bool
+		quoted       string
+	}{
+		{name: "users", quoted: "users"},
+		{name: "HR.users", quoted: "HR.users"},
+		{name: "users.*", quoted: "users.*"},
+		{name: "user name", quoted: `"user name"`},
+		{name: `my"col`, quoted: `"my""col"`},
+		{name: `"Mixed"."Case"`, quoted: `"Mixed"."Case"`},
+		{name: `"a.b".c`, quoted: `"a.b".c`},
+		{name: "level", quoted: `"LEVEL"`},
+		{name: "HR.order", quoted: `HR."ORDER"`},
+		{name: ":1", quoted: ":1"},
+		{name: "users", preserveCase: true, quoted: `"users"`},
+		{name: "HR.Users", preserveCase: true, quoted: `"HR"."Users"`},
+	} {
+		var sql strings.Builder
+		Dialector{Config: &Config{PreserveCase: tt.preserveCase}}.QuoteTo(&sql, tt.name)
+		if sql.String() != tt.quoted {
+			t.Errorf("QuoteTo(%q) with PreserveCase %v = %s, want %s", tt.name, tt.preserveCase, sql.String(), tt.quoted)
+		}
+	}
+}
+
+type pagedUser struct {
+	ID        uint
+	Name      string
+	CompanyID uint
+	Company   pagedCompany
+}
+
+type pagedCompany struct {
+	ID   uint
+	Name string
+}
+
+func TestRewriteLimit(t *testing.T) {
+	var (
+		users []pagedUser
+		names []string
+	)
+
+	for _, tt := range []struct {
+		name    string
+		version string
+		query   func(db *gorm.DB) *gorm.DB
+		sql     string
+	}{
+		{
+			name:    "offset and limit",
+			version: "19",
+			query:   func(db *gorm.DB) *gorm.DB { return db.Offset(10).Limit(5).Find(&users) },
+			sql:     "SELECT * FROM PAGED_USERS ORDER BY PAGED_USERS.ID OFFSET 10 ROWS FETCH NEXT 5 ROWS ONLY",
+		},
+		{
+			name:    "order by kept",
+			version: "19",
+			query:   func(db *gorm.DB) *gorm.DB { return db.Order("NAME").Limit(5).Find(&users) },
+			sql:     "SELECT * FROM PAGED_USERS ORDER BY NAME FETCH NEXT 5 ROWS ONLY",
+		},
+		{
+			name:    "fetch first percent",
+			version: "19",
+			query: func(db *gorm.DB) *gorm.DB {
+				return db.Order("NAME").Clauses(clauses.FetchFirst{Percent: 5, WithTies: true}).Find(&users)
+			},
+			sql: "SELECT * FROM PAGED_USERS ORDER BY NAME FETCH FIRST 5 PERCENT ROWS WITH TIES",
+		},
+		{
+			name:    "rownum limit",
+			version: "11.2",
+			query:   func(db *gorm.DB) *gorm.DB { return db.Limit(5).Find(&users) },
+			sql:     "SELECT * FROM (SELECT * FROM PAGED_USERS ORDER BY PAGED_USERS.ID) WHERE ROWNUM <= 5",
+		},
+		{
+			name:    "rownum offset",
+			version: "11.2",
+			query:   func(db *gorm.DB) *gorm.DB { return db.Offset(10).Limit(5).Find(&users) },
+			sql:     "SELECT * FROM (SELECT a.*, ROWNUM RN FROM (SELECT * FROM PAGED_USERS ORDER BY PAGED_USERS.ID) a WHERE ROWNUM <= 15) WHERE RN > 10",
+		},
+		{
+			name:    "rownum offset without limit",
+			version: "11.2",
+			query:   func(db *gorm.DB) *gorm.DB { return db.Offset(10).Find(&users) },
+			sql:     "SELECT * FROM (SELECT a.*, ROWNUM RN FROM (SELECT * FROM PAGED_USERS ORDER BY PAGED_USERS.ID) a) WHERE RN > 10",
+		},
+		{
+			// RN is left out for single column scans
+			name:    "rownum pluck",
+			version: "11.2",
+			query:   func(db *gorm.DB) *gorm.DB { return db.Model(&pagedUser{}).Offset(10).Limit(5).Pluck("name", &names) },
+			sql:     "SELECT name FROM (SELECT a.*, ROWNUM RN FROM (SELECT name FROM PAGED_USERS ORDER BY PAGED_USERS.ID) a WHERE ROWNUM <= 15) WHERE RN > 10",
+		},
+		{
+			name:    "rownum selected columns",
+			version: "11.2",
+			query:   func(db *gorm.DB) *gorm.DB { return db.Select("id", "name").Offset(10).Find(&users) },
+			sql:     "SELECT id,name FROM (SELECT a.*, ROWNUM RN FROM (SELECT id,name FROM PAGED_USERS ORDER BY PAGED_USERS.ID) a) WHERE RN > 10",
+		},
+		{
+			name:    "rownum joins",
+			version: "11.2",
+			query:   func(db *gorm.DB) *gorm.DB { return db.Joins("Company").Offset(10).Find(&users) },
+			sql: "SELECT ID,NAME,COMPANY_ID,Company__ID,Company__NAME FROM (SELECT a.*, ROWNUM RN FROM (" +
+				"SELECT PAGED_USERS.ID,PAGED_USERS.NAME,PAGED_USERS.COMPANY_ID,Company.ID AS Company__ID,Company.NAME AS Company__NAME " +
+				"FROM PAGED_USERS LEFT JOIN PAGED_COMPANIES Company ON PAGED_USERS.COMPANY_ID = Company.ID ORDER BY PAGED_USERS.ID) a) WHERE RN > 10",
+		},
+		{
+			name:    "rownum fetch first rows",
+			version: "11.2",
+			query:   func(db *gorm.DB) *gorm.DB { return db.Clauses(clauses.FetchFirst{Rows: 5}).Find(&users) },
+			sql:     "SELECT * FROM (SELECT * FROM PAGED_USERS ORDER BY PAGED_USERS.ID) WHERE ROWNUM <= 5",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			tx := tt.query(dryRun(t, Config{ServerVersion: tt.version}))
+			if tx.Error != nil {
+				t.Fatalf("failed to build query, got error %v", tx.Error)
+			}
+			if sql := tx.Statement.SQL.String(); sql != tt.sql {
+				t.Errorf("got SQL\n%s\nwant\n%s", sql, tt.sql)
+			}
+		})
+	}
+}
+
+func TestRewriteLimitUnsupported(t *testing.T) {
+	var users []pagedUser
+	for _, fetch := range []clauses.FetchFirst{{Percent: 5}, {Rows: 5, WithTies: true}} {
+		tx := dryRun(t, Config{ServerVersion: "11.2"}).Order("NAME").Clauses(fetch).Find(&users)
+		if !errors.Is(tx.Error, gorm.ErrNotImplemented) {
+			t.Errorf("%+v before 12c should fail with gorm.ErrNotImplemented, got error %v", fetch, tx.Error)
+		}
+	}
+}
+
+func TestRewriteRownumLocking(t *testing.T) {
+	// rows paged by ROWNUM come from a view, which can not be locked
+	var users []pagedUser
+	tx := dryRun(t, Config{ServerVersion: "11.2"}).Clauses(clause.Locking{Strength: "UPDATE"}).Offset(10).Limit(5).Find(&users)
+	if !errors.Is(tx.Error, gorm.ErrNotImplemented) {
+		t.Errorf("locking a ROWNUM page should fail with gorm.ErrNotImplemented, got error %v", tx.Error)
+	}
+	tx = dryRun(t, Config{ServerVersion: "11.2"}).Clauses(clause.Locking{Strength: "UPDATE"}).Find(&users)
+	if want := "SELECT * FROM PAGED_USERS FOR UPDATE"; tx.Error != nil || tx.Statement.SQL.String() != want {
+		t.Errorf("got SQL\n%s\nwant\n%s\nand error %v", tx.Statement.SQL.String(), want, tx.Error)
+	}
+}
+
+type typedModel struct {
+	ID        uint `gorm:"autoIncrement"`
+	Code      uint
+	Small     int16
+	Tiny      uint8
+	Big       int64
+	Huge      uint64
+	Amount    float64 `gorm:"precision:10;scale:2"`
+	Ratio     float32
+	Active    bool
+	Flag      bool `gorm:"serializer:yesno"`
+	Name      string
+	CreatedAt time.Time
+	Day       time.Time `gorm:"timeType:date"`
+	Stamp     time.Time `gorm:"precision:3;timeType:timestamp"`
+}
+
+type untaggedKey struct {
+	ID   uint
+	Name string
+}
+
+func TestDataTypeOf(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		config Config
+		model  interface{}
+		types  map[string]string
+	}{
+		{
+			name:   "19c",
+			config: Config{ServerVersion: "19"},
+			model:  &typedModel{},
+			types: map[string]string{
+				"ID":         "NUMBER(20) GENERATED BY DEFAULT AS IDENTITY",
+				"CODE":       "NUMBER(20)",
+				"SMALL":      "NUMBER(5)",
+				"TINY":       "NUMBER(3)",
+				"BIG":        "NUMBER(19)",
+				"HUGE":       "NUMBER(20)",
+				"AMOUNT":     "NUMBER(10,2)",
+				"RATIO":      "FLOAT",
+				"ACTIVE":     "NUMBER(1)",
+				"FLAG":       "CHAR(1)",
+				"CREATED_AT": "TIMESTAMP WITH TIME ZONE",
+				"DAY":        "DATE",
+				"STAMP":      "TIMESTAMP(3)",
+			},
+		},
+		{
+			name:   "23ai",
+			config: Config{ServerVersion: "23.4", BinaryFloat: true, Identity: IdentityOptions{Generated: "always"}},
+			model:  &typedModel{},
+			types: map[string]string{
+				"ID":     "NUMBER(20) GENERATED ALWAYS AS IDENTITY",
+				"RATIO":  "BINARY_FLOAT",
+				"ACTIVE": "BOOLEAN",
+				"FLAG":   "CHAR(1)",
+			},
+		},
+		{
+			// the sequence and trigger generate the key
+			name:   "11g",
+			config: Config{ServerVersion: "11.2"},
+			model:  &typedModel{},
+			types:  map[string]string{"ID": "NUMBER(20)"},
+		},
+		{
+			// gorm marks every integer primary key auto increment, only a tag makes it an identity
+			name:   "untagged key",
+			config: Config{ServerVersion: "19"},
+			model:  &untaggedKey{},
+			types:  map[string]string{"ID": "NUMBER(20)"},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			db := dryRun(t, tt.config)
+			stmt := &gorm.Statement{DB: db}
+			if err := stmt.Parse(tt.model); err != nil {
+				t.Fatalf("failed to parse model, got error %v", err)
+			}
+			for column, want := range tt.types {
+				field := stmt.Schema.LookUpField(column)
+				if field == nil {
+					t.Fatalf("no field for column %s", column)
+				}
+				if got := db.Dialector.DataTypeOf(field); got != want {
+					t.Errorf("data type of %s is %q, want %q", column, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestColumnCheckOf(t *testing.T) {
+	for _, tt := range []struct {
+		version   string
+		column    string
+		condition string
+	}{
+		{"19", "ACTIVE", "? IN (0, 1)"},
+		{"19", "FLAG", "? IN ('Y', 'N')"},
+		{"19", "CODE", "? >= 0"},
+		{"19", "NAME", ""},
+		{"23.4", "ACTIVE", ""},
+		{"23.4", "FLAG", "? IN ('Y', 'N')"},
+	} {
+		db := dryRun(t, Config{ServerVersion: tt.version})
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(&typedModel{}); err != nil {
+			t.Fatalf("failed to parse model, got error %v", err)
+		}
+		if _, condition := db.Dialector.(*Dialector).columnCheckOf(stmt.Schema.LookUpField(tt.column)); condition != tt.condition {
+			t.Errorf("check of %s on %s is %q, want %q", tt.column, tt.version, condition, tt.condition)
+		}
+	}
+}
@@ -1,8 +1,11 @@
 package oracle
 
 import (
+	"context"
+
 	"github.com/emirpasic/gods/sets/hashset"
 	"github.com/thoas/go-funk"
+	"gorm.io/gorm"
 )
 
 var ReservedWords = hashset.New(funk.Map(ReservedWordsList, func(s string) interface{} { return s }).([]interface{})...)
@@ -11,7 +14,42 @@ func IsReservedWord(v string) bool {
 	return ReservedWords.Contains(v)
 }
 
+// isReservedWord reports whether v is reserved, by the list of the package or the words the dialector
+// loaded from V$RESERVED_WORDS
+func (d Dialector) isReservedWord(v string) bool {
+	return IsReservedWord(v) || d.Config != nil && d.reservedWords[v]
+}
+
+// loadReservedWords loads the reserved words of the server
+func (d Dialector) loadReservedWords(db *gorm.DB) error {
+	rows, err := db.ConnPool.QueryContext(context.Background(), "SELECT KEYWORD FROM V$RESERVED_WORDS WHERE RESERVED = 'Y' OR RES_SEMI = 'Y'")
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	d.reservedWords = map[string]bool{}
+	for rows.Next() {
+		var keyword string
+		if err = rows.Scan(&keyword); err != nil {
+			return err
+		}
+		d.reservedWords[keyword] = true
+	}
+	return rows.Err()
+}
+
 var ReservedWordsList = []string{
+	// the reserved words of oracle SQL
+	"ACCESS", "ADD", "ALTER", "AUDIT", "CHECK", "CLUSTER", "COLUMN", "COLUMN_VALUE", "COMMENT", "COMPRESS",
+	"CONNECT", "CREATE", "CURRENT", "DEFAULT", "DISTINCT", "DROP", "EXCLUSIVE", "EXISTS", "FILE", "GRANT", "GROUP",
+	"HAVING", "IDENTIFIED", "IMMEDIATE", "INCREMENT", "INDEX", "INITIAL", "INTERSECT", "LOCK", "MAXEXTENTS", "MINUS",
+	"MODE", "MODIFY", "NESTED_TABLE_ID", "NOAUDIT", "NOCOMPRESS", "NOWAIT", "OFFLINE", "ONLINE", "OPTION", "PCTFREE",
+	"PRIOR", "PUBLIC", "RENAME", "RESOURCE", "REVOKE", "ROW", "ROWNUM", "ROWS", "SELECT", "SESSION", "SHARE", "SIZE",
+	"SMALLINT", "START", "SUCCESSFUL", "SYNONYM", "SYSDATE", "TABLE", "TRIGGER", "UID", "UNION", "UNIQUE", "USER",
+	"VARCHAR", "VIEW", "WHENEVER",
+	// the reserved words of OLAP DML
+
 	"AGGREGATE", "AGGREGATES", "ALL", "ALLOW", "ANALYZE", "ANCESTOR", "AND", "ANY", "AS", "ASC", "AT", "AVG", "BETWEEN",
 	"BINARY_DOUBLE", "BINARY_FLOAT", "BLOB", "BRANCH", "BUILD", "BY", "BYTE", "CASE", "CAST", "CHAR", "CHILD", "CLEAR",
 	"CLOB", "COMMIT", "COMPILE", "CONSIDER", "COUNT", "DATATYPE", "DATE", "DATE_MEASURE", "DAY", "DECIMAL", "DELETE",
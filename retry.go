@@ -0,0 +1,118 @@
+package oracle
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/godror/godror"
+	"gorm.io/gorm"
+)
+
+// RetryPolicy runs statements outside of transactions, and transactions run by Transaction, again
+// when they fail with a transient error: a deadlock (ORA-00060), a serialization failure (ORA-08177)
+// or a lost connection (ORA-03113, ORA-03135, ORA-25408). Creates, updates and deletes run in a
+// transaction of gorm's own unless gorm.Config.SkipDefaultTransaction is set
+type RetryPolicy struct {
+	// MaxRetries is how often a failed statement or transaction runs again
+	MaxRetries int
+	// Backoff is the wait before the first retry, doubling for every one after it up to MaxBackoff
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+}
+
+// rolledBackCodes leave nothing of the failed statement behind, so that it can always run again
+var rolledBackCodes = map[int]bool{60: true, 8177: true}
+
+// lostConnectionCodes leave it unknown whether the failed statement took effect, so that only
+// queries and transactions failed before their commit run again
+var lostConnectionCodes = map[int]bool{3113: true, 3135: true, 25408: true}
+
+// retryable reports whether err is transient, where replayable allows the errors leaving it unknown
+// whether the statement took effect
+func retryable(err error, replayable bool) bool {
+	var oraErr *godror.OraErr
+	if !errors.As(err, &oraErr) {
+		return false
+	}
+	return rolledBackCodes[oraErr.Code()] || replayable && lostConnectionCodes[oraErr.Code()]
+}
+
+// after waits out the backoff before a retry
+var after = time.After
+
+// run runs fc until it succeeds, fails with an error not worth retrying or runs out of retries
+func (p *RetryPolicy) run(ctx context.Context, replayable bool, fc func() error) (err error) {
+	backoff := p.Backoff
+	for retries := 0; ; retries++ {
+		if err = fc(); err == nil || retries >= p.MaxRetries || !retryable(err, replayable) {
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-after(backoff):
+		}
+		if backoff *= 2; p.MaxBackoff > 0 && backoff > p.MaxBackoff {
+			backoff = p.MaxBackoff
+		}
+	}
+}
+
+// Transaction runs fc in a transaction like db.Transaction does, and runs it again in a new one when
+// it fails with an error the retry policy of the dialector retries. A transaction that already runs
+// can not be retried from within, so nested ones are left to db.Transaction. Serializable transactions
+// begin here rather than by db.Transaction, on a session made serializable while they run
+func Transaction(db *gorm.DB, fc func(tx *gorm.DB) error, opts ...*sql.TxOptions) error {
+	var policy *RetryPolicy
+	switch dialector := db.Dialector.(type) {
+	case *Dialector:
+		policy = dialector.Retry
+	case Dialector:
+		policy = dialector.Retry
+	}
+	if _, inTransaction := db.Statement.ConnPool.(gorm.TxCommitter); inTransaction {
+		return db.Transaction(fc, opts...)
+	}
+
+	begin := func(fc func(tx *gorm.DB) error) error {
+		return db.Transaction(fc, opts...)
+	}
+	if isSerializable(opts) {
+		begin = func(fc func(tx *gorm.DB) error) error {
+			return serializable(db, func(tx *gorm.DB) error {
+				return tx.Transaction(fc)
+			})
+		}
+	}
+	if policy == nil {
+		return begin(fc)
+	}
+
+	var committing bool
+	err := policy.run(db.Statement.Context, true, func() error {
+		committing = false
+		err := begin(func(tx *gorm.DB) error {
+			err := fc(tx)
+			committing = err == nil
+			return err
+		})
+
+		// a commit losing its connection may have gone through
+		if err != nil && committing && !retryable(err, false) {
+			return &commitError{err}
+		}
+		return err
+	})
+	if commitErr, ok := err.(*commitError); ok {
+		return commitErr.error
+	}
+	return err
+}
+
+// commitError hides the error of a transaction's commit from the retry policy
+type commitError struct {
+	error
+}
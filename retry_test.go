@@ -0,0 +1,290 @@
+package oracle
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	deadlock       = newOraErr(60, 0, "deadlock detected while waiting for resource")
+	lostConnection = newOraErr(3113, 0, "end-of-file on communication channel")
+)
+
+// waiting records the backoffs of retries instead of waiting them out
+func waiting(t *testing.T) *[]time.Duration {
+	waits := new([]time.Duration)
+	after = func(d time.Duration) <-chan time.Time {
+		*waits = append(*waits, d)
+		return time.After(0)
+	}
+	t.Cleanup(func() { after = time.After })
+	return waits
+}
+
+func TestRetryable(t *testing.T) {
+	for _, tt := range []struct {
+		err        error
+		replayable bool
+		want       bool
+	}{
+		{deadlock, false, true},
+		{newOraErr(8177, 0, "can't serialize access for this transaction"), false, true},
+		{fmt.Errorf("failed to update: %w", deadlock), false, true},
+		{lostConnection, true, true},
+		{lostConnection, false, false},
+		{newOraErr(1, 0, "unique constraint (SCOTT.PK) violated"), true, false},
+		{errors.New("ORA-00060: deadlock detected"), true, false},
+	} {
+		if got := retryable(tt.err, tt.replayable); got != tt.want {
+			t.Errorf("retryable(%v, %v) = %v, want %v", tt.err, tt.replayable, got, tt.want)
+		}
+	}
+}
+
+func TestRetryPolicyRun(t *testing.T) {
+	unique := newOraErr(1, 0, "unique constraint (SCOTT.PK) violated")
+	for _, tt := range []struct {
+		name       string
+		policy     RetryPolicy
+		replayable bool
+		errs       []error
+		err        error
+		calls      int
+		waits      []time.Duration
+	}{
+		{
+			name:   "retries run out",
+			policy: RetryPolicy{MaxRetries: 2, Backoff: time.Millisecond},
+			errs:   []error{deadlock, deadlock, deadlock, deadlock},
+			err:    deadlock,
+			calls:  3,
+			waits:  []time.Duration{time.Millisecond, 2 * time.Millisecond},
+		},
+		{
+			name:   "success",
+			policy: RetryPolicy{MaxRetries: 2, Backoff: time.Millisecond},
+			errs:   []error{deadlock},
+			calls:  2,
+			waits:  []time.Duration{time.Millisecond},
+		},
+		{
+			name:   "backoff capped",
+			policy: RetryPolicy{MaxRetries: 4, Backoff: 10 * time.Millisecond, MaxBackoff: 25 * time.Millisecond},
+			errs:   []error{deadlock, deadlock, deadlock, deadlock, deadlock},
+			err:    deadlock,
+			calls:  5,
+			waits:  []time.Duration{10 * time.Millisecond, 20 * time.Millisecond, 25 * time.Millisecond, 25 * time.Millisecond},
+		},
+		{
+			name:   "not transient",
+			policy: RetryPolicy{MaxRetries: 2},
+			errs:   []error{unique},
+			err:    unique,
+			calls:  1,
+		},
+		{
+			name:       "lost connection replayed",
+			policy:     RetryPolicy{MaxRetries: 2},
+			replayable: true,
+			errs:       []error{lostConnection},
+			calls:      2,
+			waits:      []time.Duration{0},
+		},
+		{
+			name:   "lost connection not replayed",
+			policy: RetryPolicy{MaxRetries: 2},
+			errs:   []error{lostConnection},
+			err:    lostConnection,
+			calls:  1,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			waits := waiting(t)
+			var calls int
+			err := tt.policy.run(context.Background(), tt.replayable, func() error {
+				calls++
+				if calls <= len(tt.errs) {
+					return tt.errs[calls-1]
+				}
+				return nil
+			})
+
+			if calls != tt.calls {
+				t.Errorf("got %d calls, want %d", calls, tt.calls)
+			}
+			if err != tt.err {
+				t.Errorf("got error %v, want %v", err, tt.err)
+			}
+			if !reflect.DeepEqual(*waits, tt.waits) {
+				t.Errorf("got waits %v, want %v", *waits, tt.waits)
+			}
+		})
+	}
+
+	// a cancelled context stops the retries
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	var calls int
+	(&RetryPolicy{MaxRetries: 2, Backoff: time.Hour}).run(ctx, true, func() error {
+		calls++
+		return deadlock
+	})
+	if calls != 1 {
+		t.Errorf("a cancelled context should stop retrying, got %d calls", calls)
+	}
+}
+
+// failing is a driver connection failing every statement with err, counting them in calls
+type failing struct {
+	err   error
+	calls *int
+}
+
+func (f failing) Connect(context.Context) (driver.Conn, error) { return f, nil }
+func (f failing) Driver() driver.Driver                        { return nil }
+func (f failing) Prepare(string) (driver.Stmt, error)          { return nil, driver.ErrSkip }
+func (f failing) Close() error                                 { return nil }
+func (f failing) Begin() (driver.Tx, error)                    { return nil, driver.ErrSkip }
+
+func (f failing) ExecContext(context.Context, string, []driver.NamedValue) (driver.Result, error) {
+	*f.calls++
+	return nil, f.err
+}
+
+func (f failing) QueryContext(context.Context, string, []driver.NamedValue) (driver.Rows, error) {
+	*f.calls++
+	return nil, f.err
+}
+
+func TestConnPoolRetry(t *testing.T) {
+	waiting(t)
+	for _, tt := range []struct {
+		name  string
+		err   error
+		run   func(*connPool) error
+		calls int
+	}{
+		{
+			name: "exec deadlock",
+			err:  deadlock,
+			run: func(pool *connPool) error {
+				_, err := pool.ExecContext(context.Background(), "UPDATE USERS SET NAME = NAME")
+				return err
+			},
+			calls: 3,
+		},
+		{
+			// the update may have gone through before the connection was lost
+			name: "exec lost connection",
+			err:  lostConnection,
+			run: func(pool *connPool) error {
+				_, err := pool.ExecContext(context.Background(), "UPDATE USERS SET NAME = NAME")
+				return err
+			},
+			calls: 1,
+		},
+		{
+			name: "query lost connection",
+			err:  lostConnection,
+			run: func(pool *connPool) error {
+				_, err := pool.QueryContext(context.Background(), "SELECT * FROM USERS")
+				return err
+			},
+			calls: 3,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int
+			pool := &connPool{DB: sql.OpenDB(failing{err: tt.err, calls: &calls}), retry: &RetryPolicy{MaxRetries: 2}}
+			if err := tt.run(pool); !errors.Is(err, tt.err) {
+				t.Errorf("got error %v, want %v", err, tt.err)
+			}
+			if calls != tt.calls {
+				t.Errorf("got %d calls, want %d", calls, tt.calls)
+			}
+		})
+	}
+}
+
+func TestTransaction(t *testing.T) {
+	waiting(t)
+	for _, tt := range []struct {
+		name  string
+		fails map[int]error
+		errs  []error
+		err   error
+		calls int
+		sql   []string
+	}{
+		{
+			name:  "deadlock",
+			errs:  []error{deadlock},
+			calls: 2,
+			sql:   []string{"BEGIN", "ROLLBACK", "BEGIN", "COMMIT"},
+		},
+		{
+			// nothing was committed, so the transaction runs again
+			name:  "lost connection",
+			errs:  []error{lostConnection, lostConnection, lostConnection},
+			err:   lostConnection,
+			calls: 3,
+			sql:   []string{"BEGIN", "ROLLBACK", "BEGIN", "ROLLBACK", "BEGIN", "ROLLBACK"},
+		},
+		{
+			// but a commit losing its connection may have gone through
+			name:  "commit lost connection",
+			fails: map[int]error{2: lostConnection},
+			err:   lostConnection,
+			calls: 1,
+			sql:   []string{"BEGIN", "COMMIT", "ROLLBACK"},
+		},
+		{
+			// while one failing by a deadlock has not
+			name:  "commit deadlock",
+			fails: map[int]error{2: deadlock},
+			calls: 2,
+			sql:   []string{"BEGIN", "COMMIT", "ROLLBACK", "BEGIN", "COMMIT"},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			db, r := recording(t, Config{Retry: &RetryPolicy{MaxRetries: 2}})
+			r.fails = tt.fails
+			db.Statement.ConnPool = &beginner{r}
+
+			var calls int
+			err := Transaction(db, func(tx *gorm.DB) error {
+				calls++
+				if calls <= len(tt.errs) {
+					return tt.errs[calls-1]
+				}
+				return nil
+			})
+			if !errors.Is(err, tt.err) || tt.err == nil && err != nil {
+				t.Errorf("got error %v, want %v", err, tt.err)
+			}
+			var commitErr *commitError
+			if errors.As(err, &commitErr) {
+				t.Errorf("the error of a commit should be handed back as it is, got %#v", err)
+			}
+			if calls != tt.calls {
+				t.Errorf("got %d calls, want %d", calls, tt.calls)
+			}
+
+			sql := make([]string, len(r.execs))
+			for idx, exec := range r.execs {
+				sql[idx] = exec.sql
+			}
+			if !reflect.DeepEqual(sql, tt.sql) {
+				t.Errorf("got executions %q, want %q", sql, tt.sql)
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package oracle
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	gormSchema "gorm.io/gorm/schema"
+	"gorm.io/gorm/utils"
+
+	"github.com/rahmanme/oracle/clauses"
+)
+
+// PrepareReturning turns the clause.Returning of an update or delete into the RETURNING ... INTO
+// form oracle understands, binding an array out variable for every returned column
+func PrepareReturning(db *gorm.DB) {
+	stmt := db.Statement
+	if db.Error != nil || stmt.Schema == nil || stmt.SQL.Len() > 0 {
+		return
+	}
+
+	returning, ok := stmt.Clauses["RETURNING"].Expression.(clause.Returning)
+	if !ok {
+		return
+	}
+
+	var fields []*gormSchema.Field
+	if len(returning.Columns) == 0 || (len(returning.Columns) == 1 && returning.Columns[0].Name == "*") {
+		for _, dbName := range stmt.Schema.DBNames {
+			fields = append(fields, stmt.Schema.FieldsByDBName[dbName])
+		}
+	} else {
+		for _, column := range returning.Columns {
+			field := lookUpColumn(stmt.Schema, column.Name)
+			if field == nil {
+				db.AddError(fmt.Errorf("%w: returning column %s", gorm.ErrInvalidField, column.Name))
+				return
+			}
+			fields = append(fields, field)
+		}
+	}
+
+	returningInto := clauses.ReturningInto{}
+	for _, field := range fields {
+		returningInto.Variables = append(returningInto.Variables, clause.Column{Name: field.DBName})
+		returningInto.Into = append(returningInto.Into, sql.Out{Dest: outArrayOf(field, 0)})
+	}
+	stmt.AddClause(returningInto)
+}
+
+// ScanReturning sets the values handed back through the RETURNING ... INTO out binds on the model,
+// matching the elements of a slice by primary key when it can and by position otherwise
+func ScanReturning(db *gorm.DB) {
+	stmt := db.Statement
+	returning, ok := stmt.Clauses["RETURNING"].Expression.(clauses.ReturningInto)
+	if db.Error != nil || db.DryRun || !ok || len(returning.Into) == 0 {
+		return
+	}
+
+	fields := make([]*gormSchema.Field, len(returning.Variables))
+	returned := make([]reflect.Value, len(returning.Into))
+	for idx, column := range returning.Variables {
+		fields[idx] = lookUpColumn(stmt.Schema, column.Name)
+		returned[idx] = reflect.ValueOf(returning.Into[idx].(sql.Out).Dest).Elem()
+	}
+
+	setRow := func(row int, reflectValue reflect.Value) {
+		for idx, field := range fields {
+			db.AddError(field.Set(stmt.Context, reflectValue, returned[idx].Index(row).Interface()))
+		}
+	}
+
+	rows := returned[0].Len()
+	reflectValue := stmt.ReflectValue
+	switch reflectValue.Kind() {
+	case reflect.Struct:
+		if rows > 0 {
+			setRow(0, reflectValue)
+		}
+	case reflect.Slice, reflect.Array:
+		// the returned rows come in no particular order, so find them by their primary key
+		primaryIdx := make([]int, 0, len(stmt.Schema.PrimaryFields))
+		for _, primaryField := range stmt.Schema.PrimaryFields {
+			for idx, field := range fields {
+				if field == primaryField {
+					primaryIdx = append(primaryIdx, idx)
+				}
+			}
+		}
+
+		if elems, _ := gormSchema.GetIdentityFieldValuesMap(stmt.Context, reflectValue, stmt.Schema.PrimaryFields); len(elems) > 0 &&
+			len(primaryIdx) == len(stmt.Schema.PrimaryFields) {
+			for row := 0; row < rows; row++ {
+				key := make([]interface{}, len(primaryIdx))
+				for i, idx := range primaryIdx {
+					key[i] = returned[idx].Index(row).Interface()
+				}
+
+				for _, elem := range elems[utils.ToStringKey(key...)] {
+					setRow(row, elem)
+				}
+			}
+			return
+		}
+
+		for row := 0; row < rows; row++ {
+			if row >= reflectValue.Len() {
+				if reflectValue.Kind() != reflect.Slice || !reflectValue.CanSet() {
+					break
+				}
+				reflectValue.Set(reflect.Append(reflectValue, reflect.New(reflectValue.Type().Elem()).Elem()))
+				if elem := reflectValue.Index(row); elem.Kind() == reflect.Ptr {
+					elem.Set(reflect.New(elem.Type().Elem()))
+				}
+			}
+			setRow(row, reflectValue.Index(row))
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package oracle
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+
+	"gorm.io/gorm/clause"
+)
+
+func TestPrepareReturning(t *testing.T) {
+	// returned columns are named by their fields or columns in any case
+	for _, tt := range []struct {
+		name string
+		tx   func() (string, []interface{})
+		sql  string
+	}{
+		{
+			name: "update",
+			sql:  "UPDATE IDENTITY_ROWS SET NAME=:1 WHERE ID = :2 RETURNING ID,NAME INTO :3,:4",
+			tx: func() (string, []interface{}) {
+				tx := dryRun(t, Config{}).Model(&identityRow{ID: 1}).Clauses(clause.Returning{Columns: []clause.Column{{Name: "id"}, {Name: "Name"}}}).Update("Name", "b")
+				return tx.Statement.SQL.String(), tx.Statement.Vars
+			},
+		},
+		{
+			name: "delete",
+			sql:  "DELETE FROM IDENTITY_ROWS WHERE NAME = :1 RETURNING ID,NAME INTO :2,:3",
+			tx: func() (string, []interface{}) {
+				tx := dryRun(t, Config{}).Clauses(clause.Returning{}).Where("NAME = ?", "a").Delete(&identityRow{})
+				return tx.Statement.SQL.String(), tx.Statement.Vars
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			query, vars := tt.tx()
+			if query != tt.sql {
+				t.Errorf("got SQL\n%s\nwant\n%s", query, tt.sql)
+			}
+			for _, v := range vars[len(vars)-2:] {
+				if _, ok := v.(sql.Out); !ok {
+					t.Errorf("returned columns should be read into out binds, got %#v", v)
+				}
+			}
+		})
+	}
+}
+
+func TestScanReturning(t *testing.T) {
+	ids := []sql.NullInt64{{Int64: 2, Valid: true}, {Int64: 1, Valid: true}}
+	names := []string{"b", "a"}
+
+	// the rows of a slice are found by their primary key, whatever the order they come back in
+	db, r := recording(t, Config{})
+	r.outs = [][]interface{}{{ids, names}}
+	rows := []identityRow{{ID: 1}, {ID: 2}}
+	if err := db.Clauses(clause.Returning{}).Delete(&rows).Error; err != nil {
+		t.Fatalf("failed to delete, got error %v", err)
+	}
+	if want := []identityRow{{ID: 1, Name: "a"}, {ID: 2, Name: "b"}}; !reflect.DeepEqual(rows, want) {
+		t.Errorf("got %+v, want %+v", rows, want)
+	}
+
+	// without their primary keys the returned rows are appended in order
+	db, r = recording(t, Config{})
+	r.outs = [][]interface{}{{names}}
+	var deleted []identityRow
+	if err := db.Clauses(clause.Returning{Columns: []clause.Column{{Name: "name"}}}).Where("NAME IS NOT NULL").Delete(&deleted).Error; err != nil {
+		t.Fatalf("failed to delete, got error %v", err)
+	}
+	if want := []identityRow{{Name: "b"}, {Name: "a"}}; !reflect.DeepEqual(deleted, want) {
+		t.Errorf("got %+v, want %+v", deleted, want)
+	}
+
+	// and a struct takes the first one
+	db, r = recording(t, Config{})
+	r.outs = [][]interface{}{{ids, names}}
+	row := identityRow{ID: 2}
+	if err := db.Model(&row).Clauses(clause.Returning{}).Update("Name", "c").Error; err != nil {
+		t.Fatalf("failed to update, got error %v", err)
+	}
+	if row.Name != "b" {
+		t.Errorf("got %+v, want the name returned", row)
+	}
+}
@@ -0,0 +1,107 @@
+package oracle
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/thoas/go-funk"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// SequenceOptions are the options of a sequence, zero values leave the oracle defaults in place
+type SequenceOptions struct {
+	StartWith   int64
+	IncrementBy int64
+	MinValue    int64
+	MaxValue    int64
+	Cache       int64
+	NoCache     bool
+	Cycle       bool
+}
+
+// sequenceOf returns the sequence field draws its values from, e.g. gorm:"sequence:ORDERS_SEQ"
+func sequenceOf(field *schema.Field) string {
+	return field.TagSettings["SEQUENCE"]
+}
+
+// sequenceFields lists the fields of s drawing their values from a sequence
+func sequenceFields(s *schema.Schema) (fields []*schema.Field) {
+	for _, field := range s.Fields {
+		if field.DBName != "" && sequenceOf(field) != "" {
+			fields = append(fields, field)
+		}
+	}
+	return
+}
+
+// returningFields lists the fields whose values are generated by the database on create and
+// read back afterwards, those with a default value and those drawn from a sequence
+func returningFields(s *schema.Schema) []*schema.Field {
+	fields := s.FieldsWithDefaultDBValue
+	for _, field := range sequenceFields(s) {
+		if !funk.Contains(fields, field) {
+			fields = append(fields[:len(fields):len(fields)], field)
+		}
+	}
+	return fields
+}
+
+// nextVal is the expression drawing the next value of sequence
+func nextVal(stmt *gorm.Statement, sequence string) clause.Expr {
+	return clause.Expr{SQL: stmt.Quote(clause.Table{Name: sequence}) + ".NEXTVAL"}
+}
+
+// columnIndex is the position of the column named name in columns, or -1
+func columnIndex(columns []clause.Column, name string) int {
+	for idx, column := range columns {
+		if column.Name == name {
+			return idx
+		}
+	}
+	return -1
+}
+
+// isZeroValue reports whether v is a nil or zero value, or the DEFAULT gorm fills in for rows of a batch
+// without a value, leaving the database to fill it in
+func isZeroValue(v interface{}) bool {
+	return v == nil || isDefaultValue(v) || reflect.ValueOf(v).IsZero()
+}
+
+// isDefaultValue reports whether v is the DEFAULT written by Dialector.DefaultValueOf
+func isDefaultValue(v interface{}) bool {
+	expr, ok := v.(clause.Expr)
+	return ok && expr.SQL == "DEFAULT" && len(expr.Vars) == 0
+}
+
+// sql writes the options as they follow CREATE SEQUENCE, or ALTER SEQUENCE when alter is set,
+// where a new start value restarts the sequence (18c)
+func (opts SequenceOptions) sql(alter bool) string {
+	var sql strings.Builder
+	if opts.StartWith != 0 {
+		if alter {
+			sql.WriteString(" RESTART")
+		}
+		sql.WriteString(" START WITH " + strconv.FormatInt(opts.StartWith, 10))
+	}
+	if opts.IncrementBy != 0 {
+		sql.WriteString(" INCREMENT BY " + strconv.FormatInt(opts.IncrementBy, 10))
+	}
+	if opts.MinValue != 0 {
+		sql.WriteString(" MINVALUE " + strconv.FormatInt(opts.MinValue, 10))
+	}
+	if opts.MaxValue != 0 {
+		sql.WriteString(" MAXVALUE " + strconv.FormatInt(opts.MaxValue, 10))
+	}
+	if opts.NoCache {
+		sql.WriteString(" NOCACHE")
+	} else if opts.Cache != 0 {
+		sql.WriteString(" CACHE " + strconv.FormatInt(opts.Cache, 10))
+	}
+	if opts.Cycle {
+		sql.WriteString(" CYCLE")
+	}
+	return sql.String()
+}
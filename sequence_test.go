@@ -0,0 +1,45 @@
+package oracle
+
+import (
+	"errors"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+func TestSequenceOptions(t *testing.T) {
+	opts := SequenceOptions{StartWith: 100, IncrementBy: 10, MaxValue: 1000, Cache: 20, Cycle: true}
+	if sql, want := opts.sql(false), " START WITH 100 INCREMENT BY 10 MAXVALUE 1000 CACHE 20 CYCLE"; sql != want {
+		t.Errorf("got %q, want %q", sql, want)
+	}
+	if sql, want := opts.sql(true), " RESTART START WITH 100 INCREMENT BY 10 MAXVALUE 1000 CACHE 20 CYCLE"; sql != want {
+		t.Errorf("got %q, want %q", sql, want)
+	}
+	if sql, want := (SequenceOptions{Cache: 20, NoCache: true}).sql(false), " NOCACHE"; sql != want {
+		t.Errorf("got %q, want %q", sql, want)
+	}
+}
+
+func TestAlterSequence(t *testing.T) {
+	for _, tt := range []struct {
+		version string
+		opts    SequenceOptions
+		sql     string
+		err     error
+	}{
+		{"19", SequenceOptions{StartWith: 100}, "ALTER SEQUENCE USERS_SEQ RESTART START WITH 100", nil},
+		{"12.2", SequenceOptions{IncrementBy: 5}, "ALTER SEQUENCE USERS_SEQ INCREMENT BY 5", nil},
+		{"12.2", SequenceOptions{StartWith: 100}, "", gorm.ErrNotImplemented},
+	} {
+		db := dryRun(t, Config{ServerVersion: tt.version})
+		var sql string
+		db.Callback().Raw().After("gorm:raw").Register("test:sql", func(db *gorm.DB) { sql = db.Statement.SQL.String() })
+
+		if err := db.Migrator().(Migrator).AlterSequence("USERS_SEQ", tt.opts); !errors.Is(err, tt.err) {
+			t.Errorf("altering %+v on %s got error %v, want %v", tt.opts, tt.version, err, tt.err)
+		}
+		if sql != tt.sql {
+			t.Errorf("altering %+v on %s got SQL %q, want %q", tt.opts, tt.version, sql, tt.sql)
+		}
+	}
+}
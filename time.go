@@ -0,0 +1,82 @@
+package oracle
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/godror/godror"
+	"github.com/godror/godror/dsn"
+	"gorm.io/gorm/schema"
+)
+
+// TimeType is the data type time fields are kept in
+type TimeType string
+
+const (
+	// TimestampWithTimeZone keeps the time zone of each time, it is the default
+	TimestampWithTimeZone TimeType = "TIMESTAMP WITH TIME ZONE"
+	// TimestampWithLocalTimeZone keeps times in the time zone of the database, reading them back in
+	// the time zone of the session
+	TimestampWithLocalTimeZone TimeType = "TIMESTAMP WITH LOCAL TIME ZONE"
+	// Timestamp keeps times without a time zone
+	Timestamp TimeType = "TIMESTAMP"
+	// Date keeps times to the second without a time zone
+	Date TimeType = "DATE"
+)
+
+// timeTypeOf returns the data type of a time field, the dialector's time type overridden by its
+// tag, e.g. gorm:"timeType:timestamp;precision:3", with the precision of fractional seconds
+func (d Dialector) timeTypeOf(field *schema.Field) string {
+	timeType := d.TimeType
+	if value, ok := field.TagSettings["TIMETYPE"]; ok {
+		timeType = TimeType(value)
+	}
+	if timeType = TimeType(strings.ToUpper(strings.Join(strings.Fields(string(timeType)), " "))); timeType == "" {
+		timeType = TimestampWithTimeZone
+	}
+
+	switch timeType {
+	case Date:
+		return string(Date)
+	case Timestamp, TimestampWithTimeZone, TimestampWithLocalTimeZone:
+	default:
+		panic(fmt.Sprintf("invalid time type %s for field %s", timeType, field.Name))
+	}
+
+	switch {
+	case field.Precision > 9:
+		panic(fmt.Sprintf("invalid precision %d for field %s", field.Precision, field.Name))
+	case field.Precision > 0:
+		return strings.Replace(string(timeType), "TIMESTAMP", fmt.Sprintf("TIMESTAMP(%d)", field.Precision), 1)
+	}
+	return string(timeType)
+}
+
+// openWithSessionTimeZone opens the pool of DSN, setting SessionTimeZone as the time zone of every
+// session and as the location times without a time zone are read back in
+func (d Dialector) openWithSessionTimeZone() (*sql.DB, error) {
+	params, err := godror.ParseDSN(d.DSN)
+	if err != nil {
+		return nil, err
+	}
+	if params.Timezone, err = sessionLocation(d.SessionTimeZone); err != nil {
+		return nil, err
+	}
+	params.SetSessionParamOnInit("TIME_ZONE", d.SessionTimeZone)
+	return sql.OpenDB(godror.NewConnector(params)), nil
+}
+
+// sessionLocation returns the location of a session time zone, a region such as Europe/Berlin or an
+// offset such as +02:00
+func sessionLocation(timeZone string) (*time.Location, error) {
+	if location, err := time.LoadLocation(timeZone); err == nil {
+		return location, nil
+	}
+	offset, err := dsn.ParseTZ(timeZone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid session time zone %q: %w", timeZone, err)
+	}
+	return time.FixedZone(timeZone, offset), nil
+}